@@ -0,0 +1,142 @@
+package api
+
+import (
+	"crypto/sha1"
+	"database/sql"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"net/url"
+	"newsAPI/collyan"
+	"newsAPI/db"
+	"newsAPI/dedup"
+	"newsAPI/enrich"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Сколько символов текста статьи отправлять в Gemini
+const maxPromptText = 20000
+
+type AddArticleRequest struct {
+	URL      string `json:"url"`
+	Category string `json:"category"`
+}
+
+// AddArticleHandler добавляет статью по ссылке, которую прислал редактор.
+// Страница скрапится, описание генерируется Gemini, категория берётся из запроса
+// или определяется Gemini.
+func AddArticleHandler(c *gin.Context, repo db.Repository, database *sql.DB) {
+	var req AddArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	pageURL, err := url.Parse(strings.TrimSpace(req.URL))
+	if err != nil || (pageURL.Scheme != "http" && pageURL.Scheme != "https") || pageURL.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный URL статьи"})
+		return
+	}
+	categories, err := db.CategoryIDs(database)
+	if err != nil {
+		log.Printf("Ошибка при получении категорий: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить статью"})
+		return
+	}
+	if req.Category != "" && !containsString(categories, req.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная категория"})
+		return
+	}
+
+	page, err := collyan.ScrapeArticle(pageURL.String())
+	if err != nil {
+		log.Printf("Ошибка скрапинга %s: %v", pageURL, err)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Не удалось получить статью по ссылке"})
+		return
+	}
+
+	text := page.Body
+	if runes := []rune(text); len(runes) > maxPromptText {
+		text = string(runes[:maxPromptText])
+	}
+
+	// Если Gemini не ответил, описание останется пустым и статья попадёт в очередь обогащения
+	description, err := enrich.Summarize(text)
+	if err != nil {
+		log.Printf("Не удалось получить описание для %s: %v", pageURL, err)
+	}
+
+	category := req.Category
+	if category == "" {
+		var ok bool
+		if category, ok = enrich.Classify(page.Title+"\n"+text, categories); !ok {
+			category = db.DefaultCategory
+		}
+	}
+
+	published := page.PublishedAt
+	if published.IsZero() {
+		published = time.Now()
+	}
+
+	sourceName := page.SiteName
+	if sourceName == "" {
+		sourceName = strings.TrimPrefix(pageURL.Hostname(), "www.")
+	}
+
+	article := db.NewsArticle{
+		ArticleID:   manualArticleID(pageURL.String()),
+		Title:       page.Title,
+		Link:        pageURL.String(),
+		Description: description,
+		Content:     page.Body,
+		PubDate:     published.UTC().Format(time.RFC3339),
+		PublishedAt: published.UTC(),
+		ImageURL:    page.ImageURL,
+		SourceID:    "manual",
+		SourceName:  sourceName,
+		SourceURL:   pageURL.Scheme + "://" + pageURL.Host,
+		Language:    "ru",
+		Category:    []string{category},
+		SourceType:  db.SourceTypeManual,
+	}
+
+	inserted, err := repo.SaveArticle(article)
+	if err != nil {
+		log.Printf("Ошибка сохранения статьи: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить статью"})
+		return
+	}
+	existed := !inserted
+
+	// Сюжеты пока есть только в SQLite
+	if inserted && repo.Dialect() == db.DialectSQLite {
+		if _, err := dedup.Assign(database, article); err != nil {
+			log.Printf("Ошибка кластеризации статьи %s: %v", article.ArticleID, err)
+		}
+	}
+
+	status := http.StatusCreated
+	if existed {
+		status = http.StatusOK
+	}
+	c.JSON(status, article)
+}
+
+// manualArticleID строит стабильный article_id для статьи, добавленной вручную
+func manualArticleID(link string) string {
+	sum := sha1.Sum([]byte(link))
+	return "manual-" + hex.EncodeToString(sum[:16])
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
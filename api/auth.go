@@ -1,8 +1,9 @@
 package api
 
 import (
-	"database/sql"
+	"errors"
 	"net/http"
+	"newsAPI/db"
 	"os"
 	"time"
 
@@ -53,25 +54,13 @@ func generateJWT(userID int) (string, error) {
 }
 
 // RegisterHandler обрабатывает регистрацию пользователей
-func RegisterHandler(c *gin.Context, db *sql.DB) {
+func RegisterHandler(c *gin.Context, repo db.Repository) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
 		return
 	}
 
-	// Проверяем, существует ли пользователь с таким email
-	var exists int
-	err := db.QueryRow("SELECT COUNT(*) FROM users WHERE email = ?", req.Email).Scan(&exists)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при проверке существующего пользователя"})
-		return
-	}
-	if exists > 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Пользователь с таким email уже существует"})
-		return
-	}
-
 	// Хешируем пароль
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -79,17 +68,14 @@ func RegisterHandler(c *gin.Context, db *sql.DB) {
 		return
 	}
 
-	// Сохраняем пользователя в БД
-	result, err := db.Exec("INSERT INTO users (email, password) VALUES (?, ?)",
-		req.Email, string(hashedPassword))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при регистрации пользователя"})
+	// Сохраняем пользователя; email должен быть свободен
+	userID, err := repo.CreateUser(req.Email, string(hashedPassword))
+	if errors.Is(err, db.ErrUserExists) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пользователь с таким email уже существует"})
 		return
 	}
-
-	userID, err := result.LastInsertId()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при получении ID пользователя"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при регистрации пользователя"})
 		return
 	}
 
@@ -110,31 +96,28 @@ func RegisterHandler(c *gin.Context, db *sql.DB) {
 }
 
 // LoginHandler обрабатывает вход пользователей
-func LoginHandler(c *gin.Context, db *sql.DB) {
+func LoginHandler(c *gin.Context, repo db.Repository) {
 	var req LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
 		return
 	}
 
-	var userID int
-	var hashedPassword string
-	err := db.QueryRow("SELECT id, password FROM users WHERE email = ?", req.Email).
-		Scan(&userID, &hashedPassword)
+	user, err := repo.GetUserByEmail(req.Email)
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный email или пароль"})
 		return
 	}
 
 	// Проверяем пароль
-	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password))
+	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password))
 	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный email или пароль"})
 		return
 	}
 
 	// Генерируем JWT токен
-	token, err := generateJWT(userID)
+	token, err := generateJWT(int(user.ID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при генерации токена"})
 		return
@@ -143,7 +126,7 @@ func LoginHandler(c *gin.Context, db *sql.DB) {
 	c.JSON(http.StatusOK, gin.H{
 		"token": token,
 		"user": gin.H{
-			"id": userID,
+			"id": user.ID,
 		},
 	})
 }
@@ -158,17 +141,30 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		// Убираем префикс "Bearer " если он есть
-		if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-			tokenString = tokenString[7:]
+		claims, ok := parseToken(tokenString)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный токен"})
+			c.Abort()
+			return
 		}
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
-		})
+		c.Set("user_id", claims.UserID)
+		c.Next()
+	}
+}
+
+// OptionalJWTAuthMiddleware кладёт user_id в контекст, если передан токен,
+// и пропускает запрос без токена. Недействительный токен отклоняется.
+func OptionalJWTAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := c.GetHeader("Authorization")
+		if tokenString == "" {
+			c.Next()
+			return
+		}
 
-		if err != nil || !token.Valid {
+		claims, ok := parseToken(tokenString)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Недействительный токен"})
 			c.Abort()
 			return
@@ -178,3 +174,51 @@ func JWTAuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// parseToken проверяет JWT из заголовка Authorization (с префиксом "Bearer " или без него)
+func parseToken(tokenString string) (*Claims, bool) {
+	// Убираем префикс "Bearer " если он есть
+	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
+		tokenString = tokenString[7:]
+	}
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwtKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+	return claims, true
+}
+
+// contextUserID возвращает user_id, который положил в контекст JWT middleware, или 0
+func contextUserID(c *gin.Context) int64 {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0
+	}
+	return int64(userID.(int))
+}
+
+// AdminMiddleware пропускает только администраторов.
+// Должен идти после JWTAuthMiddleware, который кладёт user_id в контекст.
+func AdminMiddleware(repo db.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Токен не предоставлен"})
+			c.Abort()
+			return
+		}
+
+		isAdmin, err := repo.IsAdmin(int64(userID.(int)))
+		if err != nil || !isAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
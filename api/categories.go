@@ -0,0 +1,134 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Идентификатор категории: латиница в нижнем регистре, цифры, дефис и подчёркивание
+var categoryIDPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+type CategoryRequest struct {
+	NameRU   string `json:"name_ru"`
+	NameEN   string `json:"name_en"`
+	Position int    `json:"position"`
+}
+
+type CategoryMappingRequest struct {
+	Provider   string `json:"provider"`
+	Name       string `json:"name"`
+	CategoryID string `json:"category_id"`
+}
+
+// ListCategoriesHandler возвращает справочник категорий с сопоставлениями
+func ListCategoriesHandler(c *gin.Context, database *sql.DB) {
+	categories, err := db.ListCategories(database)
+	if err != nil {
+		log.Printf("Ошибка при получении категорий: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить категории"})
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// SaveCategoryHandler создаёт категорию или меняет её названия и порядок
+func SaveCategoryHandler(c *gin.Context, database *sql.DB) {
+	id := c.Param("id")
+	if !categoryIDPattern.MatchString(id) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID категории"})
+		return
+	}
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if strings.TrimSpace(req.NameRU) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указано название категории"})
+		return
+	}
+
+	category := db.Category{
+		ID:       id,
+		NameRU:   strings.TrimSpace(req.NameRU),
+		NameEN:   strings.TrimSpace(req.NameEN),
+		Position: req.Position,
+	}
+	if err := db.SaveCategory(database, category); err != nil {
+		log.Printf("Ошибка при сохранении категории %s: %v", id, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось сохранить категорию"})
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategoryHandler удаляет категорию вместе с её сопоставлениями
+func DeleteCategoryHandler(c *gin.Context, database *sql.DB) {
+	if err := db.DeleteCategory(database, c.Param("id")); err != nil {
+		respondCategoryError(c, err, "Категория не найдена")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SetCategoryMappingHandler сопоставляет категорию провайдера с внутренней категорией
+func SetCategoryMappingHandler(c *gin.Context, database *sql.DB) {
+	var req CategoryMappingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if !db.IsCategoryProvider(req.Provider) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный провайдер"})
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Не указана категория провайдера"})
+		return
+	}
+
+	exists, err := db.CategoryExists(database, req.CategoryID)
+	if err != nil {
+		respondCategoryError(c, err, "")
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная категория"})
+		return
+	}
+
+	mapping := db.CategoryMapping{Provider: req.Provider, Name: req.Name, CategoryID: req.CategoryID}
+	if err := db.SetCategoryMapping(database, mapping); err != nil {
+		respondCategoryError(c, err, "")
+		return
+	}
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// DeleteCategoryMappingHandler удаляет сопоставление категории провайдера
+func DeleteCategoryMappingHandler(c *gin.Context, database *sql.DB) {
+	if err := db.DeleteCategoryMapping(database, c.Param("provider"), c.Param("name")); err != nil {
+		respondCategoryError(c, err, "Сопоставление не найдено")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func respondCategoryError(c *gin.Context, err error, notFound string) {
+	if notFound != "" && errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": notFound})
+		return
+	}
+	log.Printf("Ошибка при изменении категорий: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить категории"})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListConversationsHandler возвращает вопросы пользователя к помощнику, начиная с последних.
+// Параметры: limit (1–100, по умолчанию 20), offset.
+func ListConversationsHandler(c *gin.Context, repo db.Repository) {
+	limit := 20
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit должен быть от 1 до 100"})
+			return
+		}
+		limit = n
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	userID := contextUserID(c)
+	conversations, total, err := repo.ListConversations(userID, limit, offset)
+	if err != nil {
+		log.Printf("Ошибка при получении разговоров пользователя %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversations": conversations,
+		"total":         total,
+		"limit":         limit,
+		"offset":        offset,
+	})
+}
+
+// DeleteConversationsHandler удаляет всю историю вопросов пользователя к помощнику
+func DeleteConversationsHandler(c *gin.Context, repo db.Repository) {
+	userID := contextUserID(c)
+	deleted, err := repo.DeleteConversations(userID)
+	if err != nil {
+		log.Printf("Ошибка при удалении разговоров пользователя %d: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось удалить историю"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"deleted": deleted})
+}
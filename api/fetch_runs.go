@@ -0,0 +1,105 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetFetchRuns возвращает журнал запусков загрузки новостей.
+// Фильтры: source, category, status (ok|error), from, to (RFC 3339 или YYYY-MM-DD), limit, offset.
+func GetFetchRuns(c *gin.Context, database *sql.DB) {
+	filter, ok := parseFetchRunFilter(c)
+	if !ok {
+		return
+	}
+
+	runs, err := db.ListFetchRuns(database, filter)
+	if err != nil {
+		log.Printf("Ошибка при получении журнала загрузок: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить журнал загрузок"})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetFetchRunsSummary возвращает статистику запусков по источникам и категориям
+// с теми же фильтрами, что и GetFetchRuns.
+func GetFetchRunsSummary(c *gin.Context, database *sql.DB) {
+	filter, ok := parseFetchRunFilter(c)
+	if !ok {
+		return
+	}
+
+	summary, err := db.SummarizeFetchRuns(database, filter)
+	if err != nil {
+		log.Printf("Ошибка при подсчёте статистики загрузок: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить статистику загрузок"})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+func parseFetchRunFilter(c *gin.Context) (db.FetchRunFilter, bool) {
+	filter := db.FetchRunFilter{
+		Source:   c.Query("source"),
+		Category: c.Query("category"),
+		Limit:    50,
+	}
+
+	switch c.Query("status") {
+	case "":
+	case "ok":
+		onlyErr := false
+		filter.OnlyErr = &onlyErr
+	case "error":
+		onlyErr := true
+		filter.OnlyErr = &onlyErr
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status должен быть ok или error"})
+		return filter, false
+	}
+
+	var err error
+	if filter.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр from"})
+		return filter, false
+	}
+	if filter.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр to"})
+		return filter, false
+	}
+
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit должен быть от 1 до 500"})
+			return filter, false
+		}
+		filter.Limit = limit
+	}
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	return filter, true
+}
+
+// parseTimeParam разбирает время в формате RFC 3339 или дату YYYY-MM-DD (UTC)
+func parseTimeParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"log"
 	"net/http"
+	"newsAPI/db"
 	"newsAPI/gemini"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,15 +23,19 @@ type NewsArticle struct {
 	VideoURL    string   `json:"video_url"`
 	Description string   `json:"description"`
 	Content     string   `json:"content"`
-	PubDate     string   `json:"publishedAt"`
+	PubDate     string   `json:"publishedAt"`       // RFC 3339 в UTC, пусто — если дата неизвестна
+	PubDateRaw  string   `json:"pub_date_original"` // дата в том виде, в каком её прислал источник
 	ImageURL    string   `json:"urlToImage"`
 	SourceID    string   `json:"source_id"`
 	SourceName  string   `json:"source_name"`
 	SourceURL   string   `json:"url"`
 	Language    string   `json:"language"`
 	Country     string   `json:"country"`
-	Tags        string   `json:"tags"`
+	Tags        string   `json:"tags"`       // первая категория, для старых клиентов
+	Categories  []string `json:"categories"` // id категорий из справочника categories
 	Sentiment   string   `json:"sentiment"`
+	Pinned      bool     `json:"pinned"`           // закреплена модератором, идёт в начале ленты
+	Hidden      bool     `json:"hidden,omitempty"` // скрыта модератором, видна только в /admin
 }
 
 type Request struct {
@@ -40,84 +47,108 @@ type Response struct {
 }
 
 // GetNews обрабатывает запрос на получение новостей
-func GetNews(c *gin.Context, database *sql.DB) {
+func GetNews(c *gin.Context, repo db.Repository, database *sql.DB) {
 	limit := 15
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
-	category := c.DefaultQuery("category", "")
 
-	validCategories := []string{"top", "sports", "technology", "business", "science", "entertainment", "health", "world", "politics", "environment", "food"}
-	validCategory := false
-
-	for _, valid := range validCategories {
-		if category == valid {
-			validCategory = true
-			break
-		}
+	filter, ok := newsFilter(c, database)
+	if !ok {
+		return
 	}
+	filter.Limit = limit
+	filter.Offset = offset
 
-	log.Printf("Получение новостей с лимитом %d, смещением %d, категорией: %s", limit, offset, category)
-
-	var rows *sql.Rows
-	var err error
-
-	if validCategory {
-		rows, err = database.Query(
-			"SELECT article_id, title, link, keywords, creator, video_url, description, content, pub_date, image_url, source_id, source_name, source_url, language, country, category, sentiment "+
-				"FROM news WHERE category LIKE ? ORDER BY pub_date DESC LIMIT ? OFFSET ?",
-			"%"+category+"%", limit, offset,
-		)
-	} else {
-		rows, err = database.Query(
-			"SELECT article_id, title, link, keywords, creator, video_url, description, content, pub_date, image_url, source_id, source_name, source_url, language, country, category, sentiment "+
-				"FROM news ORDER BY pub_date DESC LIMIT ? OFFSET ?",
-			limit, offset,
-		)
-	}
+	log.Printf("Получение новостей с лимитом %d, смещением %d, категорией: %s", limit, offset, c.Query("category"))
 
+	articles, err := repo.ListArticles(filter)
 	if err != nil {
 		log.Printf("Ошибка при выполнении запроса: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
 		return
 	}
-	defer rows.Close()
-
-	var news []NewsArticle
 
-	for rows.Next() {
-		var n NewsArticle
-		var keywordsStr, creatorStr, countryStr, categoryStr string
+	c.JSON(http.StatusOK, toAPIArticles(articles))
+}
 
-		err := rows.Scan(
-			&n.ArticleID, &n.Title, &n.Link, &keywordsStr, &creatorStr, &n.VideoURL,
-			&n.Description, &n.Content, &n.PubDate, &n.ImageURL, &n.SourceID, &n.SourceName, &n.SourceURL,
-			&n.Language, &countryStr, &categoryStr, &n.Sentiment,
-		)
-		if err != nil {
-			log.Printf("Ошибка при сканировании строки: %v", err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обработке данных"})
-			return
-		}
+// newsFilter разбирает фильтры /news и /news/search. Справочник категорий берётся из локальной БД.
+// При ошибке отвечает клиенту и возвращает false.
+func newsFilter(c *gin.Context, database *sql.DB) (db.ArticleFilter, bool) {
+	filter := db.ArticleFilter{
+		Keyword: c.Query("keyword"),
+		Country: c.Query("country"),
+		Creator: c.Query("creator"),
+		Channel: strings.TrimPrefix(c.Query("channel"), "@"),
+	}
 
-		// Разбиваем строки с разделителями в срезы
-		n.Keywords = strings.Split(keywordsStr, ",")
-		n.Creator = strings.Split(creatorStr, ",")
-		n.Country = strings.TrimSpace(strings.Split(countryStr, ",")[0])
-		n.Tags = strings.TrimSpace(strings.Split(categoryStr, ",")[0])
+	// Фильтруем только по категориям из справочника, неизвестная категория — без фильтра
+	category := c.Query("category")
+	validCategory, err := db.CategoryExists(database, category)
+	if err != nil {
+		log.Printf("Ошибка при проверке категории: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return filter, false
+	}
+	if validCategory {
+		filter.Category = category
+	}
 
-		news = append(news, n)
+	// Период публикации: from включительно, to не включительно
+	if filter.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр from"})
+		return filter, false
+	}
+	if filter.To, err = parseTimeParam(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный параметр to"})
+		return filter, false
 	}
 
-	if err := rows.Err(); err != nil {
-		log.Printf("Ошибка при обработке строк результата: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обработке данных"})
-		return
+	return filter, true
+}
+
+// toAPIArticle переводит статью из хранилища в формат ответа API
+func toAPIArticle(a db.NewsArticle) NewsArticle {
+	n := NewsArticle{
+		ArticleID:   a.ArticleID,
+		Title:       a.Title,
+		Link:        a.Link,
+		Keywords:    a.Keywords,
+		Creator:     a.Creator,
+		VideoURL:    a.VideoURL,
+		Description: a.Description,
+		Content:     a.Content,
+		PubDateRaw:  a.PubDate,
+		ImageURL:    a.ImageURL,
+		SourceID:    a.SourceID,
+		SourceName:  a.SourceName,
+		SourceURL:   a.SourceURL,
+		Language:    a.Language,
+		Categories:  a.Category,
+		Sentiment:   a.Sentiment,
+		Pinned:      a.Pinned,
+		Hidden:      a.Hidden,
+	}
+	if !a.PublishedAt.IsZero() {
+		n.PubDate = a.PublishedAt.UTC().Format(time.RFC3339)
 	}
+	if len(a.Country) > 0 {
+		n.Country = a.Country[0]
+	}
+	if len(a.Category) > 0 {
+		n.Tags = a.Category[0]
+	}
+	return n
+}
 
-	c.JSON(http.StatusOK, news)
+func toAPIArticles(articles []db.NewsArticle) []NewsArticle {
+	news := make([]NewsArticle, 0, len(articles))
+	for _, a := range articles {
+		news = append(news, toAPIArticle(a))
+	}
+	return news
 }
 
 // GeminiAsk обрабатывает запросы к Gemini API
-func GeminiAsk(c *gin.Context) {
+func GeminiAsk(c *gin.Context, repo db.Repository) {
 	var req Request
 
 	// Декодируем JSON из тела запроса в структуру Request
@@ -128,6 +159,34 @@ func GeminiAsk(c *gin.Context) {
 	userQuery := req.Prompt
 	// Получаем ответ от функции geminiResponse
 	responseContent := gemini.GeminiResponse("Напиши кратко ответ на вопрос: " + userQuery)
+
+	if err := repo.SaveConversation(contextUserID(c), userQuery, responseContent); err != nil {
+		log.Printf("Ошибка сохранения разговора: %v", err)
+	}
+
 	// Отправляем JSON-ответ с полученным ответом
 	c.JSON(http.StatusOK, Response{Content: responseContent})
 }
+
+// GetNewsCounts возвращает число статей по значениям измерения:
+// keywords, creators, countries или categories
+func GetNewsCounts(c *gin.Context, repo db.Repository) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный limit"})
+		return
+	}
+
+	counts, err := repo.CountArticleValues(c.Param("dimension"), limit)
+	if errors.Is(err, db.ErrUnknownDimension) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Неизвестное измерение"})
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка при подсчёте статей: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	c.JSON(http.StatusOK, counts)
+}
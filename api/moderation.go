@@ -0,0 +1,152 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ModerateArticleRequest — правки статьи; отсутствующие поля не меняются
+type ModerateArticleRequest struct {
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	ImageURL    *string   `json:"image_url"`
+	Categories  *[]string `json:"categories"`
+	Hidden      *bool     `json:"hidden"`
+	Pinned      *bool     `json:"pinned"`
+}
+
+// ModerateArticleHandler скрывает, закрепляет, правит поля и категории статьи.
+// Каждое изменение записывается в журнал модерации с id администратора.
+func ModerateArticleHandler(c *gin.Context, repo db.Repository, database *sql.DB) {
+	var req ModerateArticleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+	if req.Title == nil && req.Description == nil && req.ImageURL == nil &&
+		req.Categories == nil && req.Hidden == nil && req.Pinned == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Нет изменений"})
+		return
+	}
+
+	edit := db.ArticleEdit{
+		Title:       trimmed(req.Title),
+		Description: trimmed(req.Description),
+		ImageURL:    trimmed(req.ImageURL),
+		Categories:  req.Categories,
+		Hidden:      req.Hidden,
+		Pinned:      req.Pinned,
+	}
+	if edit.Title != nil && *edit.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Заголовок не может быть пустым"})
+		return
+	}
+
+	if edit.Categories != nil {
+		known, err := db.CategoryIDs(database)
+		if err != nil {
+			log.Printf("Ошибка при получении категорий: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить статью"})
+			return
+		}
+		if len(*edit.Categories) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Нужна хотя бы одна категория"})
+			return
+		}
+		for _, category := range *edit.Categories {
+			if !containsString(known, category) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная категория: " + category})
+				return
+			}
+		}
+	}
+
+	articleID := c.Param("id")
+	changes, err := repo.ModerateArticle(articleID, contextUserID(c), edit)
+	if errors.Is(err, db.ErrArticleNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Статья не найдена"})
+		return
+	}
+	if err != nil {
+		log.Printf("Ошибка модерации статьи %s: %v", articleID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить статью"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"article_id": articleID,
+		"changes":    changes,
+	})
+}
+
+// ListHiddenArticlesHandler возвращает скрытые модераторами статьи, чтобы их можно было вернуть
+func ListHiddenArticlesHandler(c *gin.Context, repo db.Repository) {
+	limit, offset, ok := moderationPage(c)
+	if !ok {
+		return
+	}
+
+	articles, err := repo.ListArticles(db.ArticleFilter{Hidden: true, Limit: limit, Offset: offset})
+	if err != nil {
+		log.Printf("Ошибка при получении скрытых статей: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	c.JSON(http.StatusOK, toAPIArticles(articles))
+}
+
+// GetModerationLog возвращает журнал модерации, начиная с последних записей.
+// Фильтры: article_id, limit, offset.
+func GetModerationLog(c *gin.Context, repo db.Repository) {
+	limit, offset, ok := moderationPage(c)
+	if !ok {
+		return
+	}
+
+	entries, err := repo.ModerationLog(db.ModerationFilter{
+		ArticleID: c.Query("article_id"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		log.Printf("Ошибка при получении журнала модерации: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// moderationPage разбирает limit (1–500, по умолчанию 50) и offset
+func moderationPage(c *gin.Context) (int, int, bool) {
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit должен быть от 1 до 500"})
+			return 0, 0, false
+		}
+		limit = n
+	}
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset, true
+}
+
+func trimmed(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	v := strings.TrimSpace(*s)
+	return &v
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RevisionResponse — ревизия статьи и отличия от предыдущей
+type RevisionResponse struct {
+	Revision    int              `json:"revision"`
+	ContentHash string           `json:"content_hash"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Changes     []db.FieldChange `json:"changes"`
+}
+
+// GetArticleRevisions возвращает историю изменений заголовка, описания и текста статьи.
+// Для скрытой модератором статьи отвечает, что статья не найдена.
+func GetArticleRevisions(c *gin.Context, database *sql.DB) {
+	articleID := c.Param("id")
+
+	exists, err := db.ArticleVisible(database, articleID)
+	if err != nil {
+		log.Printf("Ошибка при проверке статьи %s: %v", articleID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Статья не найдена"})
+		return
+	}
+
+	revisions, err := db.ListRevisions(database, articleID)
+	if err != nil {
+		log.Printf("Ошибка при получении ревизий статьи %s: %v", articleID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	// Первая ревизия сравнивается с пустой статьей, остальные — с предыдущей
+	response := make([]RevisionResponse, 0, len(revisions))
+	var prev db.ArticleRevision
+	for _, r := range revisions {
+		response = append(response, RevisionResponse{
+			Revision:    r.Revision,
+			ContentHash: r.ContentHash,
+			CreatedAt:   r.CreatedAt,
+			Changes:     db.DiffRevisions(prev, r),
+		})
+		prev = r
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"article_id": articleID,
+		"revisions":  response,
+	})
+}
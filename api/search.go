@@ -0,0 +1,60 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchResult — найденная статья с подсвеченными совпадениями
+type SearchResult struct {
+	NewsArticle
+	TitleHighlight string `json:"title_highlight"`
+	Snippet        string `json:"snippet"`
+}
+
+// SearchNews ищет статьи по заголовку, описанию, тексту и ключевым словам.
+// Принимает те же фильтры, что и /news; результаты упорядочены по релевантности.
+func SearchNews(c *gin.Context, repo db.Repository, database *sql.DB) {
+	limit := 15
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	query := strings.TrimSpace(c.Query("q"))
+	if _, ok := db.SearchMatch(query); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Пустой поисковый запрос"})
+		return
+	}
+
+	filter, ok := newsFilter(c, database)
+	if !ok {
+		return
+	}
+	filter.Limit = limit
+	filter.Offset = offset
+
+	hits, err := repo.SearchArticles(query, filter)
+	if err != nil {
+		log.Printf("Ошибка поиска %q: %v", query, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, h := range hits {
+		results = append(results, SearchResult{
+			NewsArticle:    toAPIArticle(h.Article),
+			TitleHighlight: h.TitleHighlight,
+			Snippet:        h.Snippet,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"results": results,
+	})
+}
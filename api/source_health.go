@@ -0,0 +1,37 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"newsAPI/db"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetSourceHealth возвращает статистику по источникам статей (source_id)
+// и по опрашиваемым лентам из news_sources, включая автоматически выключенные.
+// Выключенную ленту можно включить обратно через PATCH /admin/sources/:id.
+func GetSourceHealth(c *gin.Context, database *sql.DB) {
+	sources, err := db.ListSourceHealth(database)
+	if err != nil {
+		log.Printf("Ошибка при подсчёте статистики источников: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить статистику источников"})
+		return
+	}
+
+	feeds, err := db.ListSources(database)
+	if err != nil {
+		log.Printf("Ошибка при получении источников: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить источники"})
+		return
+	}
+	if feeds == nil {
+		feeds = []db.FeedSource{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sources": sources,
+		"feeds":   feeds,
+	})
+}
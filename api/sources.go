@@ -0,0 +1,143 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"newsAPI/db"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SourceRequest struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	URL      string `json:"url"`
+	Category string `json:"category"`
+	Language string `json:"language"`
+	Enabled  *bool  `json:"enabled"`
+}
+
+type SourceUpdateRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ListSourcesHandler возвращает все источники новостей
+func ListSourcesHandler(c *gin.Context, database *sql.DB) {
+	sources, err := db.ListSources(database)
+	if err != nil {
+		log.Printf("Ошибка при получении источников: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось получить источники"})
+		return
+	}
+	c.JSON(http.StatusOK, sources)
+}
+
+// AddSourceHandler добавляет новый источник новостей
+func AddSourceHandler(c *gin.Context, database *sql.DB) {
+	var req SourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	if req.Kind == "" {
+		req.Kind = db.SourceKindRSS
+	}
+	if req.Kind != db.SourceKindRSS {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестный тип источника"})
+		return
+	}
+	if u, err := url.Parse(req.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный URL источника"})
+		return
+	}
+	if req.Name == "" {
+		req.Name = req.URL
+	}
+	if req.Category == "" {
+		req.Category = db.DefaultCategory
+	}
+	exists, err := db.CategoryExists(database, req.Category)
+	if err != nil {
+		log.Printf("Ошибка при проверке категории: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось добавить источник"})
+		return
+	}
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная категория"})
+		return
+	}
+	if req.Language == "" {
+		req.Language = "ru"
+	}
+
+	source := db.FeedSource{
+		Kind:     req.Kind,
+		Name:     req.Name,
+		URL:      req.URL,
+		Category: req.Category,
+		Language: req.Language,
+		Enabled:  req.Enabled == nil || *req.Enabled,
+	}
+
+	id, err := db.AddSource(database, source)
+	if err != nil {
+		log.Printf("Ошибка при добавлении источника: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось добавить источник"})
+		return
+	}
+	source.ID = id
+
+	c.JSON(http.StatusCreated, source)
+}
+
+// UpdateSourceHandler включает или выключает источник
+func UpdateSourceHandler(c *gin.Context, database *sql.DB) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID источника"})
+		return
+	}
+
+	var req SourceUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		return
+	}
+
+	if err := db.SetSourceEnabled(database, id, req.Enabled); err != nil {
+		respondSourceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "enabled": req.Enabled})
+}
+
+// DeleteSourceHandler удаляет источник
+func DeleteSourceHandler(c *gin.Context, database *sql.DB) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Некорректный ID источника"})
+		return
+	}
+
+	if err := db.DeleteSource(database, id); err != nil {
+		respondSourceError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondSourceError(c *gin.Context, err error) {
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Источник не найден"})
+		return
+	}
+	log.Printf("Ошибка при изменении источника: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось изменить источник"})
+}
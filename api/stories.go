@@ -0,0 +1,91 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StoryResponse — сюжет: одна статья-представитель и её копии из других источников
+type StoryResponse struct {
+	ID             int64         `json:"id"`
+	Size           int           `json:"size"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Representative NewsArticle   `json:"representative"`
+	Articles       []NewsArticle `json:"articles"`
+	Sources        []string      `json:"sources"`
+}
+
+// GetStories возвращает сюжеты с представителем, остальными статьями и списком источников
+func GetStories(c *gin.Context, database *sql.DB) {
+	limit := 15
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	category := c.DefaultQuery("category", "")
+
+	stories, err := db.ListStories(database, category, limit, offset)
+	if err != nil {
+		log.Printf("Ошибка при получении сюжетов: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	response := make([]StoryResponse, 0, len(stories))
+	for _, story := range stories {
+		articles, err := storyArticles(database, story.ID)
+		if err != nil {
+			log.Printf("Ошибка при получении статей сюжета %d: %v", story.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при обработке данных"})
+			return
+		}
+		if len(articles) == 0 {
+			continue
+		}
+
+		item := StoryResponse{
+			ID:        story.ID,
+			Size:      story.Size,
+			UpdatedAt: story.UpdatedAt,
+			Articles:  []NewsArticle{},
+			Sources:   []string{},
+		}
+
+		// Представитель — первая статья сюжета; если её уже нет, берём самую раннюю
+		repIndex := 0
+		for i, a := range articles {
+			if a.ArticleID == story.RepresentativeID {
+				repIndex = i
+				break
+			}
+		}
+
+		seenSources := make(map[string]bool)
+		for i, a := range articles {
+			if i == repIndex {
+				item.Representative = a
+			} else {
+				item.Articles = append(item.Articles, a)
+			}
+			if a.SourceName != "" && !seenSources[a.SourceName] {
+				seenSources[a.SourceName] = true
+				item.Sources = append(item.Sources, a.SourceName)
+			}
+		}
+
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func storyArticles(database *sql.DB, storyID int64) ([]NewsArticle, error) {
+	articles, err := db.StoryArticles(database, storyID)
+	if err != nil {
+		return nil, err
+	}
+	return toAPIArticles(articles), nil
+}
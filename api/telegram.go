@@ -0,0 +1,118 @@
+package api
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"newsAPI/db"
+	"newsAPI/parser"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TelegramMessage — сообщение канала в ответе /telegram/messages
+type TelegramMessage struct {
+	ArticleID    string `json:"article_id,omitempty"` // статья в /news; у сообщений без текста её нет
+	MessageID    int64  `json:"message_id"`
+	Channel      string `json:"channel"` // имя канала без @ или его id
+	ChannelTitle string `json:"channel_title"`
+	Text         string `json:"text"`
+	Date         string `json:"date"`      // RFC 3339 в UTC, пусто — если дата неизвестна
+	MediaURL     string `json:"media_url"` // ссылка на /telegram/media/..., пусто — без медиа
+	Link         string `json:"link"`      // ссылка на сообщение в Telegram, если у канала есть имя
+}
+
+// Медиафайлы, которые можно отдавать, и их типы
+var telegramMediaTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+}
+
+// Имя файла так, как его составляет fetch_messages.py: без каталогов и служебных символов
+var telegramMediaName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// GetTelegramMessages возвращает сообщения Telegram каналов, начиная с новых.
+// Параметры: channel (имя без @ или id), limit (1–100, по умолчанию 20), offset.
+func GetTelegramMessages(c *gin.Context, database *sql.DB) {
+	filter := db.TelegramPostFilter{
+		Channel: strings.TrimPrefix(c.Query("channel"), "@"),
+		Limit:   20,
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit должен быть от 1 до 100"})
+			return
+		}
+		filter.Limit = n
+	}
+	filter.Offset, _ = strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	posts, err := db.ListTelegramPosts(database, filter)
+	if err != nil {
+		log.Printf("Ошибка при получении сообщений Telegram: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Не удалось выполнить запрос"})
+		return
+	}
+
+	messages := make([]TelegramMessage, 0, len(posts))
+	for _, post := range posts {
+		m := TelegramMessage{
+			MessageID:    post.MessageID,
+			Channel:      post.Channel(),
+			ChannelTitle: post.ChannelTitle,
+			Text:         parser.CleanTelegramText(post.Text),
+		}
+		if m.Text != "" {
+			m.ArticleID = post.ArticleID()
+		}
+		if !post.Date.IsZero() {
+			m.Date = post.Date.Format(time.RFC3339)
+		}
+		if name := post.MediaName(); name != "" {
+			m.MediaURL = parser.TelegramMediaPath + name
+		}
+		if post.ChannelUsername != "" {
+			m.Link = "https://t.me/" + post.ChannelUsername + "/" + strconv.FormatInt(post.MessageID, 10)
+		}
+		messages = append(messages, m)
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// ServeTelegramMedia отдаёт картинку из каталога mediaDir.
+// Принимаются только имена файлов без каталогов с расширением из telegramMediaTypes,
+// символические ссылки и всё, что не является обычным файлом, не отдаются.
+func ServeTelegramMedia(c *gin.Context, mediaDir string) {
+	name := c.Param("name")
+	contentType, ok := telegramMediaTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok || !telegramMediaName.MatchString(name) || strings.Contains(name, "..") {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+
+	path := filepath.Join(mediaDir, name)
+	info, err := os.Lstat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Файл не найден"})
+		return
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.File(path)
+}
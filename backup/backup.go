@@ -0,0 +1,306 @@
+package backup
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"newsAPI/config"
+	"newsAPI/db"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Снимки называются news-20060102T150405Z.db, поэтому сортировка по имени совпадает с сортировкой по времени
+const (
+	snapshotPrefix = "news-"
+	snapshotSuffix = ".db"
+	snapshotLayout = "20060102T150405Z"
+)
+
+// Пометка снимка, который Restore делает перед заменой БД
+const preRestoreSuffix = "pre-restore"
+
+// Snapshot — файл снимка в каталоге резервных копий
+type Snapshot struct {
+	Path      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Create делает снимок database в cfg.Dir, проверяет его PRAGMA integrity_check
+// и только после этого даёт ему постоянное имя. suffix добавляется к имени (например, "pre-restore").
+func Create(database *sql.DB, cfg config.BackupConfig, suffix string) (Snapshot, error) {
+	if err := os.MkdirAll(cfg.Dir, 0755); err != nil {
+		return Snapshot{}, err
+	}
+
+	now := time.Now().UTC()
+	name := snapshotPrefix + now.Format(snapshotLayout)
+	if suffix != "" {
+		name += "-" + suffix
+	}
+	path := filepath.Join(cfg.Dir, name+snapshotSuffix)
+	tmp := path + ".tmp"
+
+	os.Remove(tmp)
+	if err := db.BackupTo(database, tmp); err != nil {
+		os.Remove(tmp)
+		return Snapshot{}, fmt.Errorf("копирование: %w", err)
+	}
+	if err := Verify(tmp); err != nil {
+		os.Remove(tmp)
+		return Snapshot{}, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return Snapshot{}, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return Snapshot{Path: path, CreatedAt: now, Size: info.Size()}, nil
+}
+
+// Verify проверяет целостность файла снимка
+func Verify(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	snapshot, err := db.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	if err := db.IntegrityCheck(snapshot); err != nil {
+		return fmt.Errorf("снимок %s повреждён: %w", path, err)
+	}
+	return nil
+}
+
+// List возвращает снимки из каталога, начиная с самого нового
+func List(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, snapshotSuffix) {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, snapshotPrefix)
+		if len(stamp) < len(snapshotLayout) {
+			continue
+		}
+		createdAt, err := time.Parse(snapshotLayout, stamp[:len(snapshotLayout)])
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, Snapshot{Path: filepath.Join(dir, name), CreatedAt: createdAt, Size: info.Size()})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].CreatedAt.After(snapshots[j].CreatedAt) })
+	return snapshots, nil
+}
+
+// Latest возвращает самый новый снимок, не считая снимков pre-restore: иначе повторное
+// восстановление последнего снимка откатило бы предыдущее. false — подходящих снимков нет.
+func Latest(dir string) (Snapshot, bool, error) {
+	snapshots, err := List(dir)
+	if err != nil {
+		return Snapshot{}, false, err
+	}
+	for _, s := range snapshots {
+		if !strings.HasSuffix(s.Path, "-"+preRestoreSuffix+snapshotSuffix) {
+			return s, true, nil
+		}
+	}
+	return Snapshot{}, false, nil
+}
+
+// Rotate удаляет снимки сверх cfg.Keep и старше cfg.MaxAge. Самый новый снимок остаётся всегда.
+func Rotate(cfg config.BackupConfig) ([]Snapshot, error) {
+	snapshots, err := List(cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var removed []Snapshot
+	for i, s := range snapshots {
+		if i == 0 {
+			continue
+		}
+		tooMany := cfg.Keep > 0 && i >= cfg.Keep
+		tooOld := cfg.MaxAge > 0 && now.Sub(s.CreatedAt) > cfg.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		if err := os.Remove(s.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, s)
+	}
+	return removed, nil
+}
+
+// Restore проверяет снимок и копирует его в database. Перед этим текущая БД
+// сохраняется снимком с пометкой pre-restore, чтобы восстановление можно было откатить.
+func Restore(database *sql.DB, cfg config.BackupConfig, path string) (Snapshot, error) {
+	if err := Verify(path); err != nil {
+		return Snapshot{}, err
+	}
+	if err := checkSnapshotVersion(path); err != nil {
+		return Snapshot{}, err
+	}
+
+	safety, err := Create(database, cfg, preRestoreSuffix)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("снимок текущей БД перед восстановлением: %w", err)
+	}
+
+	if err := db.RestoreFrom(database, path); err != nil {
+		return safety, fmt.Errorf("восстановление: %w (текущая БД сохранена в %s)", err, safety.Path)
+	}
+	if err := db.IntegrityCheck(database); err != nil {
+		return safety, fmt.Errorf("после восстановления: %w (прежняя БД сохранена в %s)", err, safety.Path)
+	}
+	return safety, nil
+}
+
+// checkSnapshotVersion не даёт восстановить снимок со схемой новее, чем знает приложение
+func checkSnapshotVersion(path string) error {
+	snapshot, err := db.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+
+	version, err := db.SchemaVersion(snapshot)
+	if err != nil {
+		return err
+	}
+	latest, err := db.LatestVersion(snapshot)
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("схема снимка версии %d новее, чем знает приложение (%d)", version, latest)
+	}
+	return nil
+}
+
+// Scheduler делает снимки по расписанию backup.interval и ротирует их.
+// Первый снимок делается через интервал после запуска.
+type Scheduler struct {
+	database *sql.DB
+
+	mu      sync.Mutex
+	cfg     config.BackupConfig
+	started bool
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewScheduler(database *sql.DB) *Scheduler {
+	return &Scheduler{
+		database: database,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Apply запускает расписание или применяет новую конфигурацию со следующего снимка
+func (s *Scheduler) Apply(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+	s.cfg = cfg.Backup
+
+	if !s.started {
+		s.started = true
+		go s.loop()
+	}
+}
+
+// Stop останавливает расписание и ждёт завершения текущего снимка
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if !s.started || s.stopped {
+		s.mu.Unlock()
+		return
+	}
+	s.stopped = true
+	close(s.stop)
+	s.mu.Unlock()
+
+	// Ждём без блокировки: loop читает конфигурацию через s.config()
+	<-s.done
+}
+
+func (s *Scheduler) config() config.BackupConfig {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cfg
+}
+
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	for {
+		// С интервалом 0 снимки выключены, но конфигурацию перечитываем раз в минуту
+		wait := s.config().Interval
+		if wait <= 0 {
+			wait = time.Minute
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-s.stop:
+			return
+		}
+
+		cfg := s.config()
+		if cfg.Interval <= 0 {
+			continue
+		}
+		if _, err := Run(s.database, cfg); err != nil {
+			log.Printf("Ошибка резервного копирования: %v", err)
+		}
+	}
+}
+
+// Run делает снимок, ротирует старые и пишет итог в лог
+func Run(database *sql.DB, cfg config.BackupConfig) (Snapshot, error) {
+	snapshot, err := Create(database, cfg, "")
+	if err != nil {
+		return snapshot, err
+	}
+	log.Printf("Снимок БД: %s (%.1f МБ)", snapshot.Path, float64(snapshot.Size)/(1<<20))
+
+	removed, err := Rotate(cfg)
+	for _, r := range removed {
+		log.Printf("Удалён старый снимок %s", r.Path)
+	}
+	return snapshot, err
+}
@@ -0,0 +1,142 @@
+package collyan
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gocolly/colly"
+)
+
+// Article — данные, извлечённые со страницы статьи
+type Article struct {
+	Title       string
+	Body        string
+	ImageURL    string
+	PublishedAt time.Time // нулевое, если дата не найдена
+	SiteName    string
+}
+
+var publishedLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ScrapeArticle загружает страницу и извлекает заголовок, текст, главное изображение
+// и дату публикации. Сначала используются метатеги Open Graph, затем разметка страницы.
+func ScrapeArticle(pageURL string) (Article, error) {
+	var article Article
+	var ogTitle, htmlTitle, h1 string
+	var published []string
+	var paragraphs []string
+
+	c := colly.NewCollector()
+
+	c.OnHTML("meta", func(e *colly.HTMLElement) {
+		key := e.Attr("property")
+		if key == "" {
+			key = e.Attr("name")
+		}
+		if key == "" {
+			key = e.Attr("itemprop")
+		}
+		content := strings.TrimSpace(e.Attr("content"))
+		if content == "" {
+			return
+		}
+
+		switch key {
+		case "og:title":
+			ogTitle = content
+		case "og:image", "twitter:image":
+			if article.ImageURL == "" {
+				article.ImageURL = content
+			}
+		case "og:site_name":
+			article.SiteName = content
+		case "article:published_time", "datePublished", "pubdate", "date":
+			published = append(published, content)
+		}
+	})
+
+	c.OnHTML("title", func(e *colly.HTMLElement) {
+		if htmlTitle == "" {
+			htmlTitle = strings.TrimSpace(e.Text)
+		}
+	})
+
+	c.OnHTML("h1", func(e *colly.HTMLElement) {
+		if h1 == "" {
+			h1 = strings.TrimSpace(e.Text)
+		}
+	})
+
+	c.OnHTML("time[datetime]", func(e *colly.HTMLElement) {
+		published = append(published, e.Attr("datetime"))
+	})
+
+	c.OnHTML("p", func(e *colly.HTMLElement) {
+		if text := strings.TrimSpace(e.Text); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+	})
+
+	var visitErr error
+	c.OnError(func(r *colly.Response, err error) {
+		visitErr = err
+	})
+
+	if err := c.Visit(pageURL); err != nil {
+		return article, err
+	}
+	if visitErr != nil {
+		return article, visitErr
+	}
+
+	article.Title = firstNonEmpty(ogTitle, h1, htmlTitle)
+	article.Body = strings.Join(paragraphs, "\n\n")
+
+	for _, value := range published {
+		if t, ok := parsePublished(value); ok {
+			article.PublishedAt = t
+			break
+		}
+	}
+
+	// Относительная ссылка на изображение превращается в абсолютную
+	if article.ImageURL != "" {
+		if base, err := url.Parse(pageURL); err == nil {
+			if img, err := base.Parse(article.ImageURL); err == nil {
+				article.ImageURL = img.String()
+			}
+		}
+	}
+
+	if article.Title == "" && article.Body == "" {
+		return article, errors.New("на странице не найден текст статьи")
+	}
+	return article, nil
+}
+
+func parsePublished(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	for _, layout := range publishedLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
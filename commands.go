@@ -0,0 +1,400 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"newsAPI/backup"
+	"newsAPI/config"
+	"newsAPI/db"
+	"newsAPI/dedup"
+	"newsAPI/enrich"
+	"newsAPI/fetcher"
+	"newsAPI/parser"
+	"newsAPI/retention"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runCommand выполняет служебную команду вместо запуска HTTP сервера
+func runCommand(name string, args []string) {
+	switch name {
+	case "reenrich":
+		cmdReenrich(args)
+	case "cluster-stories":
+		cmdClusterStories(args)
+	case "backfill":
+		cmdBackfill(args)
+	case "replay":
+		cmdReplay(args)
+	case "migrate":
+		cmdMigrate(args)
+	case "retention":
+		cmdRetention(args)
+	case "backup":
+		cmdBackup(args)
+	case "restore":
+		cmdRestore(args)
+	default:
+		log.Fatalf("Неизвестная команда: %s (доступны: reenrich, cluster-stories, backfill, replay, migrate, retention, backup, restore)", name)
+	}
+}
+
+// cmdReenrich ставит в очередь все статьи с пустым описанием или описанием "error"
+// и обрабатывает очередь до конца
+func cmdReenrich(args []string) {
+	fs := flag.NewFlagSet("reenrich", flag.ExitOnError)
+	enqueueOnly := fs.Bool("enqueue-only", false, "только поставить в очередь, обработает запущенный сервер")
+	fs.Parse(args)
+
+	_, cfg := loadConfig()
+
+	database, err := db.InitDB()
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer database.Close()
+
+	queued, err := db.EnqueueMissingDescriptions(database)
+	if err != nil {
+		log.Fatalf("Ошибка постановки в очередь: %v", err)
+	}
+	log.Printf("Поставлено в очередь статей: %d", queued)
+
+	if *enqueueOnly {
+		return
+	}
+
+	processed, err := enrich.NewPool(database, cfg.Enrichment).Drain()
+	if err != nil {
+		log.Fatalf("Ошибка обработки очереди: %v", err)
+	}
+	log.Printf("Обработано задач: %d", processed)
+}
+
+// cmdClusterStories распределяет по сюжетам статьи, сохранённые до появления кластеризации
+func cmdClusterStories(args []string) {
+	fs := flag.NewFlagSet("cluster-stories", flag.ExitOnError)
+	fs.Parse(args)
+
+	database, err := db.InitDB()
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer database.Close()
+
+	articles, err := db.ArticlesWithoutStory(database)
+	if err != nil {
+		log.Fatalf("Ошибка получения статей: %v", err)
+	}
+
+	stories := make(map[int64]bool)
+	for _, article := range articles {
+		storyID, err := dedup.Assign(database, article)
+		if err != nil {
+			log.Fatalf("Ошибка кластеризации статьи %s: %v", article.ArticleID, err)
+		}
+		stories[storyID] = true
+	}
+	log.Printf("Обработано статей: %d, сюжетов: %d", len(articles), len(stories))
+}
+
+// cmdBackfill загружает архив newsdata.io за диапазон дат.
+// Повторный запуск с теми же параметрами продолжает с последней сохранённой страницы.
+func cmdBackfill(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	fromFlag := fs.String("from", "", "первый день, YYYY-MM-DD (обязательно)")
+	toFlag := fs.String("to", "", "последний день, YYYY-MM-DD (по умолчанию — вчера)")
+	categoriesFlag := fs.String("categories", "", "категории через запятую (по умолчанию — включённые в конфигурации)")
+	maxPages := fs.Int("max-pages", 0, "максимум страниц на категорию за день, 0 — без ограничения")
+	wait := fs.Bool("wait", false, "ждать сброса суточной квоты вместо выхода")
+	fs.Parse(args)
+
+	apiKey := os.Getenv("NEWSDATA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("NEWSDATA_API_KEY не найден в переменных окружения")
+	}
+
+	from, err := time.Parse("2006-01-02", *fromFlag)
+	if err != nil {
+		log.Fatalf("Некорректный -from: %q", *fromFlag)
+	}
+	to := time.Now().UTC().AddDate(0, 0, -1).Truncate(24 * time.Hour)
+	if *toFlag != "" {
+		if to, err = time.Parse("2006-01-02", *toFlag); err != nil {
+			log.Fatalf("Некорректный -to: %q", *toFlag)
+		}
+	}
+	if to.Before(from) {
+		log.Fatal("-to раньше -from")
+	}
+
+	_, cfg := loadConfig()
+	categories := backfillCategories(cfg, *categoriesFlag)
+	if len(categories) == 0 {
+		log.Fatal("Нет категорий для загрузки")
+	}
+
+	database, err := db.InitDB()
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer database.Close()
+
+	repo, err := db.OpenRepository(cfg.Database.DSN, database)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к хранилищу: %v", err)
+	}
+	defer repo.Close()
+
+	// Ctrl+C останавливает загрузку; прогресс уже сохранён постранично
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+
+	backfill := &fetcher.Backfill{
+		APIKey:         apiKey,
+		Repo:           repo,
+		Database:       database,
+		Gate:           fetcher.NewGate(database, cfg.Newsdata.RateLimit, stop),
+		MaxPagesPerDay: *maxPages,
+		WaitForQuota:   *wait,
+		Stop:           stop,
+	}
+	if err := backfill.Run(categories, from, to); err != nil {
+		log.Fatalf("Backfill остановлен: %v. Повторите команду, чтобы продолжить", err)
+	}
+	log.Printf("Backfill за %s — %s завершён", from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
+
+// backfillCategories выбирает категории из конфигурации; неизвестные получают настройки по умолчанию
+func backfillCategories(cfg *config.Config, names string) []config.CategoryConfig {
+	if names == "" {
+		var enabled []config.CategoryConfig
+		for _, category := range cfg.Newsdata.Categories {
+			if category.IsEnabled() {
+				enabled = append(enabled, category)
+			}
+		}
+		return enabled
+	}
+
+	known := make(map[string]config.CategoryConfig)
+	for _, category := range cfg.Newsdata.Categories {
+		known[category.Name] = category
+	}
+
+	var categories []config.CategoryConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		category, ok := known[name]
+		if !ok {
+			category = config.CategoryConfig{Name: name, CategorySettings: cfg.Newsdata.Defaults}
+		}
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// cmdReplay прогоняет записанные ответы newsdata.io через разбор и сохранение без сети.
+// Фикстуры записываются сервером, запущенным с NEWSDATA_RECORD_DIR.
+func cmdReplay(args []string) {
+	fs := flag.NewFlagSet("replay", flag.ExitOnError)
+	dir := fs.String("dir", "fixtures/newsdata", "каталог с фикстурами")
+	fs.Parse(args)
+
+	_, cfg := loadConfig()
+
+	database, err := db.InitDB()
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer database.Close()
+
+	repo, err := db.OpenRepository(cfg.Database.DSN, database)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к хранилищу: %v", err)
+	}
+	defer repo.Close()
+
+	replayed, err := parser.ReplayFixtures(*dir, repo, database)
+	if err != nil {
+		log.Fatalf("Ошибка воспроизведения: %v", err)
+	}
+	log.Printf("Воспроизведено фикстур: %d", replayed)
+}
+
+// cmdMigrate управляет версией схемы БД: migrate up | down [-steps N] | status [-postgres].
+// С -postgres команда работает с PostgreSQL из database.dsn вместо news.db.
+func cmdMigrate(args []string) {
+	if len(args) == 0 {
+		log.Fatal("Использование: migrate up | down [-steps N] | status [-postgres]")
+	}
+
+	fs := flag.NewFlagSet("migrate "+args[0], flag.ExitOnError)
+	steps := fs.Int("steps", 1, "сколько миграций откатить (для down)")
+	postgres := fs.Bool("postgres", false, "мигрировать PostgreSQL из database.dsn")
+	fs.Parse(args[1:])
+
+	open := db.Open
+	if *postgres {
+		_, cfg := loadConfig()
+		if !db.IsPostgresDSN(cfg.Database.DSN) {
+			log.Fatal("database.dsn в конфигурации не указывает на PostgreSQL")
+		}
+		open = func() (*sql.DB, error) { return db.OpenPostgresDB(cfg.Database.DSN) }
+	}
+
+	database, err := open()
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	switch args[0] {
+	case "up":
+		applied, err := db.MigrateUp(database)
+		for _, m := range applied {
+			log.Printf("Применена миграция %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка миграции: %v", err)
+		}
+		if len(applied) == 0 {
+			log.Println("Схема БД уже актуальна")
+		}
+	case "down":
+		if *steps < 1 {
+			log.Fatal("-steps должен быть не меньше 1")
+		}
+		reverted, err := db.MigrateDown(database, *steps)
+		for _, m := range reverted {
+			log.Printf("Откачена миграция %04d_%s", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка отката миграции: %v", err)
+		}
+	case "status":
+		statuses, err := db.MigrationStatuses(database)
+		if err != nil {
+			log.Fatalf("Ошибка получения статуса миграций: %v", err)
+		}
+		for _, s := range statuses {
+			applied := "не применена"
+			if s.AppliedAt != nil {
+				applied = "применена " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, applied)
+		}
+	default:
+		log.Fatalf("Неизвестное действие migrate: %s (доступны: up, down, status)", args[0])
+	}
+}
+
+// cmdRetention один раз выполняет очистку по срокам хранения из конфигурации.
+// С -dry-run только печатает, что будет удалено.
+func cmdRetention(args []string) {
+	fs := flag.NewFlagSet("retention", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "ничего не удалять, только показать")
+	verbose := fs.Bool("v", false, "перечислить удаляемые медиафайлы")
+	fs.Parse(args)
+
+	_, cfg := loadConfig()
+
+	database, err := db.InitDB()
+	if err != nil {
+		log.Fatalf("Ошибка инициализации БД: %v", err)
+	}
+	defer database.Close()
+
+	report, err := retention.Run(database, cfg.Retention, *dryRun)
+	if err != nil {
+		log.Fatalf("Ошибка очистки: %v", err)
+	}
+	if *verbose {
+		for _, path := range report.MediaFiles {
+			fmt.Println(path)
+		}
+	}
+	log.Printf("Очистка по срокам хранения: %s", report)
+}
+
+// cmdBackup делает снимок news.db через online backup API и ротирует старые снимки.
+// Безопасно запускать при работающем сервере.
+func cmdBackup(args []string) {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	list := fs.Bool("list", false, "только показать существующие снимки")
+	fs.Parse(args)
+
+	_, cfg := loadConfig()
+
+	if *list {
+		snapshots, err := backup.List(cfg.Backup.Dir)
+		if err != nil {
+			log.Fatalf("Ошибка чтения каталога снимков: %v", err)
+		}
+		for _, s := range snapshots {
+			fmt.Printf("%s\t%s\t%.1f МБ\n", s.Path, s.CreatedAt.Local().Format("2006-01-02 15:04:05"), float64(s.Size)/(1<<20))
+		}
+		return
+	}
+
+	database, err := db.Open()
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	if _, err := backup.Run(database, cfg.Backup); err != nil {
+		log.Fatalf("Ошибка резервного копирования: %v", err)
+	}
+}
+
+// cmdRestore восстанавливает news.db из снимка: -file путь или -latest.
+// Текущая БД предварительно сохраняется снимком с пометкой pre-restore.
+func cmdRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	file := fs.String("file", "", "путь к снимку")
+	latest := fs.Bool("latest", false, "взять самый новый снимок из каталога backup.dir (кроме снимков pre-restore)")
+	fs.Parse(args)
+
+	_, cfg := loadConfig()
+
+	path := *file
+	if *latest {
+		snapshot, ok, err := backup.Latest(cfg.Backup.Dir)
+		if err != nil {
+			log.Fatalf("Ошибка чтения каталога снимков: %v", err)
+		}
+		if !ok {
+			log.Fatalf("В %s нет снимков", cfg.Backup.Dir)
+		}
+		path = snapshot.Path
+	}
+	if path == "" {
+		log.Fatal("Использование: restore -file <снимок> | -latest")
+	}
+
+	database, err := db.Open()
+	if err != nil {
+		log.Fatalf("Ошибка открытия БД: %v", err)
+	}
+	defer database.Close()
+
+	safety, err := backup.Restore(database, cfg.Backup, path)
+	if err != nil {
+		log.Fatalf("Ошибка восстановления: %v", err)
+	}
+	log.Printf("БД восстановлена из %s, прежняя версия сохранена в %s", path, safety.Path)
+}
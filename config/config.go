@@ -0,0 +1,389 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath — путь к файлу конфигурации по умолчанию
+const DefaultPath = "config.yaml"
+
+// Config — настройки загрузки новостей
+type Config struct {
+	Newsdata   NewsdataConfig   `yaml:"newsdata"`
+	Feeds      FeedsConfig      `yaml:"feeds"`
+	Enrichment EnrichmentConfig `yaml:"enrichment"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Retention  RetentionConfig  `yaml:"retention"`
+	Backup     BackupConfig     `yaml:"backup"`
+	Telegram   TelegramConfig   `yaml:"telegram"`
+}
+
+// TelegramConfig — перенос сообщений каналов, собранных скриптами telegram/scripts, в ленту
+type TelegramConfig struct {
+	// ImportInterval — как часто переносить новые сообщения из telegram_messages в news, 0 — не переносить
+	ImportInterval time.Duration `yaml:"import_interval"`
+	// MediaDir — каталог медиафайлов, которые скачивает fetch_messages.py; отдаётся по /telegram/media/.
+	// Применяется только при запуске процесса.
+	MediaDir string `yaml:"media_dir"`
+}
+
+// BackupConfig — снимки news.db через online backup API SQLite
+type BackupConfig struct {
+	// Dir — каталог снимков
+	Dir string `yaml:"dir"`
+	// Interval — как часто делать снимок, 0 — только командой backup
+	Interval time.Duration `yaml:"interval"`
+	// Keep — сколько последних снимков хранить, 0 — без ограничения
+	Keep int `yaml:"keep"`
+	// MaxAge — удалять снимки старше, 0 — без ограничения. Последний снимок не удаляется никогда.
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// Действия над статьями с истёкшим сроком хранения
+const (
+	RetentionArchive = "archive" // перенести в news_archive со сжатым content
+	RetentionDelete  = "delete"  // удалить совсем
+)
+
+// RetentionConfig — сроки хранения статей и сообщений Telegram в news.db.
+// Срок 0 означает «хранить всегда».
+type RetentionConfig struct {
+	// Interval — как часто запускать очистку, 0 — только командой retention
+	Interval time.Duration `yaml:"interval"`
+	Action   string        `yaml:"action"`
+	// MaxAge — срок для статей, к которым не подошло ни одно правило ниже
+	MaxAge time.Duration `yaml:"max_age"`
+	// Categories — сроки по id категории; у статьи с несколькими категориями берётся наибольший
+	Categories map[string]time.Duration `yaml:"categories"`
+	// SourceTypes — сроки по типу источника (newsdata, rss, manual, telegram), если не подошла категория
+	SourceTypes map[string]time.Duration `yaml:"source_types"`
+	// MediaDir — каталог медиафайлов Telegram; файлы без сообщения в БД удаляются.
+	// По умолчанию — telegram.media_dir.
+	MediaDir string `yaml:"media_dir"`
+	// Vacuum — выполнять VACUUM и ANALYZE после очистки, если что-то было удалено
+	Vacuum bool `yaml:"vacuum"`
+}
+
+// DatabaseConfig — хранилище статей, пользователей и разговоров.
+// Применяется только при запуске процесса.
+type DatabaseConfig struct {
+	// DSN — postgres://... для PostgreSQL; пусто — локальная SQLite (news.db).
+	// Переменные окружения вида ${PGPASSWORD} раскрываются.
+	DSN string `yaml:"dsn"`
+	// AllowLimited — запускать сервер с PostgreSQL, хотя обогащение, сюжеты, ревизии
+	// и сроки хранения для статей в нём не работают. Без этого сервер не запускается.
+	AllowLimited bool `yaml:"allow_limited"`
+}
+
+// FeedsConfig — опрос источников из таблицы news_sources (RSS/Atom)
+type FeedsConfig struct {
+	Interval time.Duration `yaml:"interval"`
+	// MaxConsecutiveFailures — после стольких ошибок подряд источник выключается, 0 — никогда
+	MaxConsecutiveFailures int `yaml:"max_consecutive_failures"`
+}
+
+// EnrichmentConfig — очередь обогащения статей (скрапинг + описание от Gemini).
+// Применяется только при запуске процесса.
+type EnrichmentConfig struct {
+	Workers      int           `yaml:"workers"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BackoffBase  time.Duration `yaml:"backoff_base"`
+	BackoffMax   time.Duration `yaml:"backoff_max"`
+}
+
+// NewsdataConfig — расписание запросов к newsdata.io
+type NewsdataConfig struct {
+	// MaxPages — сколько страниц nextPage читать за один запуск
+	MaxPages   int              `yaml:"max_pages"`
+	RateLimit  RateLimitConfig  `yaml:"rate_limit"`
+	Defaults   CategorySettings `yaml:"defaults"`
+	Categories []CategoryConfig `yaml:"categories"`
+}
+
+// RateLimitConfig — ограничения на частоту запросов и суточную квоту кредитов
+type RateLimitConfig struct {
+	// MinGap — минимальная пауза между любыми двумя запросами к API
+	MinGap time.Duration `yaml:"min_gap"`
+	// DailyQuota — сколько кредитов доступно за сутки (UTC), 0 — без ограничения
+	DailyQuota int `yaml:"daily_quota"`
+	// QuotaReserve — сколько кредитов оставлять неизрасходованными
+	QuotaReserve int `yaml:"quota_reserve"`
+	// BackoffBase и BackoffMax — границы экспоненциальной задержки после ошибок
+	BackoffBase time.Duration `yaml:"backoff_base"`
+	BackoffMax  time.Duration `yaml:"backoff_max"`
+}
+
+// CategorySettings — параметры, которые можно задать по умолчанию и переопределить для категории
+type CategorySettings struct {
+	Interval  time.Duration `yaml:"interval"`
+	Languages []string      `yaml:"languages"`
+	Countries []string      `yaml:"countries"`
+}
+
+// CategoryConfig — настройки одной категории
+type CategoryConfig struct {
+	Name             string `yaml:"name"`
+	Enabled          *bool  `yaml:"enabled"`
+	CategorySettings `yaml:",inline"`
+}
+
+// IsEnabled возвращает true, если категория не выключена явно
+func (c CategoryConfig) IsEnabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// Default возвращает конфигурацию с прежним набором категорий и расписанием,
+// которое укладывается в бесплатную суточную квоту newsdata.io
+func Default() *Config {
+	names := []string{"top", "health", "politics", "sports", "business", "science", "food"}
+	cfg := &Config{
+		Newsdata: NewsdataConfig{
+			MaxPages: 1,
+			RateLimit: RateLimitConfig{
+				DailyQuota:   200,
+				QuotaReserve: 10,
+			},
+			Defaults: CategorySettings{
+				Interval:  2 * time.Hour,
+				Languages: []string{"ru"},
+				Countries: []string{"ru"},
+			},
+		},
+		Feeds: FeedsConfig{
+			MaxConsecutiveFailures: 5,
+		},
+		Telegram: TelegramConfig{
+			ImportInterval: 5 * time.Minute,
+		},
+	}
+	for _, name := range names {
+		cfg.Newsdata.Categories = append(cfg.Newsdata.Categories, CategoryConfig{Name: name})
+	}
+	cfg.applyDefaults()
+	return cfg
+}
+
+// Load читает конфигурацию из YAML файла.
+// Если файла нет, возвращается Default().
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("ошибка разбора %s: %w", path, err)
+	}
+
+	cfg.applyDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация %s: %w", path, err)
+	}
+
+	return &cfg, nil
+}
+
+// applyDefaults подставляет значения из defaults в категории, где они не заданы.
+// Явно пустой список (languages: []) означает «без фильтра».
+func (c *Config) applyDefaults() {
+	if c.Feeds.Interval == 0 {
+		c.Feeds.Interval = 10 * time.Minute
+	}
+
+	e := &c.Enrichment
+	if e.Workers < 1 {
+		e.Workers = 2
+	}
+	if e.MaxAttempts < 1 {
+		e.MaxAttempts = 5
+	}
+	if e.PollInterval == 0 {
+		e.PollInterval = 10 * time.Second
+	}
+	if e.BackoffBase == 0 {
+		e.BackoffBase = time.Minute
+	}
+	if e.BackoffMax == 0 {
+		e.BackoffMax = 6 * time.Hour
+	}
+
+	if c.Retention.Action == "" {
+		c.Retention.Action = RetentionArchive
+	}
+	if c.Telegram.MediaDir == "" {
+		c.Telegram.MediaDir = "telegram/telegram/media"
+	}
+	if c.Retention.MediaDir == "" {
+		c.Retention.MediaDir = c.Telegram.MediaDir
+	}
+	if c.Backup.Dir == "" {
+		c.Backup.Dir = "backups"
+	}
+
+	n := &c.Newsdata
+	if n.MaxPages < 1 {
+		n.MaxPages = 1
+	}
+	if n.RateLimit.MinGap == 0 {
+		n.RateLimit.MinGap = 5 * time.Second
+	}
+	if n.RateLimit.BackoffBase == 0 {
+		n.RateLimit.BackoffBase = time.Minute
+	}
+	if n.RateLimit.BackoffMax == 0 {
+		n.RateLimit.BackoffMax = time.Hour
+	}
+	if n.Defaults.Interval == 0 {
+		n.Defaults.Interval = 10 * time.Minute
+	}
+
+	for i := range n.Categories {
+		cat := &n.Categories[i]
+		if cat.Interval == 0 {
+			cat.Interval = n.Defaults.Interval
+		}
+		if cat.Languages == nil {
+			cat.Languages = n.Defaults.Languages
+		}
+		if cat.Countries == nil {
+			cat.Countries = n.Defaults.Countries
+		}
+	}
+}
+
+func (c *Config) validate() error {
+	rl := c.Newsdata.RateLimit
+	if rl.DailyQuota < 0 || rl.QuotaReserve < 0 {
+		return fmt.Errorf("квота и резерв не могут быть отрицательными")
+	}
+	if rl.DailyQuota > 0 && rl.QuotaReserve >= rl.DailyQuota {
+		return fmt.Errorf("резерв квоты должен быть меньше суточной квоты")
+	}
+	if rl.BackoffMax < rl.BackoffBase {
+		return fmt.Errorf("backoff_max меньше backoff_base")
+	}
+	if c.Feeds.Interval < time.Minute {
+		return fmt.Errorf("feeds: интервал меньше минуты")
+	}
+	if c.Feeds.MaxConsecutiveFailures < 0 {
+		return fmt.Errorf("feeds: max_consecutive_failures не может быть отрицательным")
+	}
+	if c.Enrichment.BackoffMax < c.Enrichment.BackoffBase {
+		return fmt.Errorf("enrichment: backoff_max меньше backoff_base")
+	}
+	if err := c.Retention.validate(); err != nil {
+		return fmt.Errorf("retention: %w", err)
+	}
+	if c.Backup.Interval != 0 && c.Backup.Interval < time.Minute {
+		return fmt.Errorf("backup: интервал меньше минуты")
+	}
+	if c.Backup.Keep < 0 || c.Backup.MaxAge < 0 {
+		return fmt.Errorf("backup: keep и max_age не могут быть отрицательными")
+	}
+	if c.Telegram.ImportInterval != 0 && c.Telegram.ImportInterval < time.Minute {
+		return fmt.Errorf("telegram: интервал меньше минуты")
+	}
+
+	seen := make(map[string]bool)
+	for _, cat := range c.Newsdata.Categories {
+		if cat.Name == "" {
+			return fmt.Errorf("категория без имени")
+		}
+		if seen[cat.Name] {
+			return fmt.Errorf("категория %q указана дважды", cat.Name)
+		}
+		seen[cat.Name] = true
+
+		if cat.Interval < time.Minute {
+			return fmt.Errorf("категория %q: интервал меньше минуты", cat.Name)
+		}
+	}
+	return nil
+}
+
+// MaxDailyCredits оценивает, сколько кредитов в сутки может потратить расписание,
+// если каждый запуск категории читает все max_pages страниц
+func (n NewsdataConfig) MaxDailyCredits() int {
+	credits := 0
+	for _, cat := range n.Categories {
+		if !cat.IsEnabled() || cat.Interval <= 0 {
+			continue
+		}
+		runs := int((24*time.Hour + cat.Interval - 1) / cat.Interval)
+		credits += runs * n.MaxPages
+	}
+	return credits
+}
+
+// QuotaWarning возвращает предупреждение, если расписание категорий не укладывается
+// в суточную квоту за вычетом резерва; пусто — укладывается или квота не задана
+func (c *Config) QuotaWarning() string {
+	rl := c.Newsdata.RateLimit
+	if rl.DailyQuota == 0 {
+		return ""
+	}
+	budget := rl.DailyQuota - rl.QuotaReserve
+	credits := c.Newsdata.MaxDailyCredits()
+	if credits <= budget {
+		return ""
+	}
+	return fmt.Sprintf("расписание newsdata может потратить до %d кредитов в сутки при доступных %d: "+
+		"загрузка встанет до конца суток (UTC), увеличьте интервалы или уменьшите max_pages", credits, budget)
+}
+
+func (r RetentionConfig) validate() error {
+	if r.Action != RetentionArchive && r.Action != RetentionDelete {
+		return fmt.Errorf("action должен быть %s или %s", RetentionArchive, RetentionDelete)
+	}
+	if r.Interval != 0 && r.Interval < time.Hour {
+		return fmt.Errorf("интервал меньше часа")
+	}
+	if r.MaxAge < 0 {
+		return fmt.Errorf("max_age не может быть отрицательным")
+	}
+	for name, age := range r.Categories {
+		if age < 0 {
+			return fmt.Errorf("категория %q: срок не может быть отрицательным", name)
+		}
+	}
+	for name, age := range r.SourceTypes {
+		if age < 0 {
+			return fmt.Errorf("тип источника %q: срок не может быть отрицательным", name)
+		}
+	}
+	return nil
+}
+
+// MaxAgeFor возвращает срок хранения статьи: по категории (наибольший из подходящих),
+// иначе по типу источника, иначе MaxAge. 0 — хранить всегда.
+func (r RetentionConfig) MaxAgeFor(sourceType string, categories []string) time.Duration {
+	var age time.Duration
+	matched := false
+	for _, category := range categories {
+		if a, ok := r.Categories[category]; ok {
+			if a == 0 {
+				return 0
+			}
+			matched = true
+			if a > age {
+				age = a
+			}
+		}
+	}
+	if matched {
+		return age
+	}
+	if a, ok := r.SourceTypes[sourceType]; ok {
+		return a
+	}
+	return r.MaxAge
+}
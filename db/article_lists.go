@@ -0,0 +1,115 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrUnknownDimension — запрошено измерение, для которого нет таблицы
+var ErrUnknownDimension = errors.New("неизвестное измерение")
+
+// Таблицы списковых полей статьи по названию измерения
+var ArticleListTables = map[string]string{
+	"keywords":   "article_keywords",
+	"creators":   "article_creators",
+	"countries":  "article_countries",
+	"categories": "article_categories",
+}
+
+// ValueCount — сколько статей имеют значение измерения
+type ValueCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// mergeArticleListsTx дописывает ключевые слова, страны и категории статьи к уже
+// сохранённым, а авторов заменяет, если провайдер их прислал
+func mergeArticleListsTx(tx sqlTx, article NewsArticle) error {
+	if len(article.Creator) > 0 {
+		if _, err := tx.Exec("DELETE FROM article_creators WHERE article_id = ?", article.ArticleID); err != nil {
+			return err
+		}
+	}
+
+	for table, values := range map[string][]string{
+		"article_keywords":   article.Keywords,
+		"article_creators":   article.Creator,
+		"article_countries":  article.Country,
+		"article_categories": article.Category,
+	} {
+		if err := appendArticleValuesTx(tx, table, article.ArticleID, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// appendArticleValuesTx добавляет в конец списка статьи значения, которых там ещё нет
+func appendArticleValuesTx(tx sqlTx, table, articleID string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	existing, err := articleValuesTx(tx, table, articleID)
+	if err != nil {
+		return err
+	}
+	merged := mergeLists(existing, values)
+
+	for i := len(existing); i < len(merged); i++ {
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO "+table+" (article_id, value, position) VALUES (?, ?, ?)",
+			articleID, merged[i], i,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func articleValuesTx(tx sqlTx, table, articleID string) ([]string, error) {
+	rows, err := tx.Query("SELECT value FROM "+table+" WHERE article_id = ? ORDER BY position", articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// CountArticleValues считает видимые статьи по значениям измерения, начиная с самых частых
+func CountArticleValues(db *sql.DB, dimension string, limit int) ([]ValueCount, error) {
+	table, ok := ArticleListTables[dimension]
+	if !ok {
+		return nil, ErrUnknownDimension
+	}
+
+	rows, err := db.Query(
+		`SELECT value, COUNT(*) AS n FROM `+table+`
+		WHERE article_id IN (SELECT article_id FROM news WHERE hidden_at IS NULL)
+		GROUP BY value ORDER BY n DESC, value LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []ValueCount{}
+	for rows.Next() {
+		var c ValueCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
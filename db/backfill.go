@@ -0,0 +1,41 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BackfillProgress — контрольная точка исторической загрузки категории за один день
+type BackfillProgress struct {
+	Category string
+	Day      string // YYYY-MM-DD
+	NextPage string // токен страницы, с которой продолжить
+	Pages    int
+	Done     bool
+}
+
+// GetBackfillProgress возвращает контрольную точку; для новой пары категория/день — пустую
+func GetBackfillProgress(db *sql.DB, category, day string) (BackfillProgress, error) {
+	p := BackfillProgress{Category: category, Day: day}
+	err := db.QueryRow(
+		"SELECT next_page, pages, done FROM backfill_progress WHERE category = ? AND day = ?",
+		category, day,
+	).Scan(&p.NextPage, &p.Pages, &p.Done)
+	if err == sql.ErrNoRows {
+		return p, nil
+	}
+	return p, err
+}
+
+// SaveBackfillProgress сохраняет контрольную точку
+func SaveBackfillProgress(db *sql.DB, p BackfillProgress) error {
+	_, err := exec(db,
+		`INSERT INTO backfill_progress (category, day, next_page, pages, done, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(category, day) DO UPDATE SET
+			next_page = excluded.next_page, pages = excluded.pages,
+			done = excluded.done, updated_at = excluded.updated_at`,
+		p.Category, p.Day, p.NextPage, p.Pages, p.Done, time.Now().UTC(),
+	)
+	return err
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Копирование идёт порциями, чтобы между шагами могли писать загрузчики
+const (
+	backupPagesPerStep = 1024
+	backupStepPause    = 10 * time.Millisecond
+)
+
+// OpenFile открывает произвольный файл SQLite (снимок) без проверки схемы
+func OpenFile(path string) (*sql.DB, error) {
+	return sql.Open("sqlite3", path)
+}
+
+// BackupTo делает согласованный снимок БД в файл path через online backup API SQLite.
+// Запись в database во время копирования не блокируется надолго.
+func BackupTo(database *sql.DB, path string) error {
+	dst, err := OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+
+	return copyDatabase(dst, database)
+}
+
+// RestoreFrom заменяет содержимое database снимком из файла path.
+// Копирование идёт через тот же backup API, поэтому открытые соединения
+// после завершения видят уже восстановленную БД.
+func RestoreFrom(database *sql.DB, path string) error {
+	src, err := OpenFile(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	return copyDatabase(database, src)
+}
+
+// IntegrityCheck выполняет PRAGMA integrity_check и возвращает ошибку с её выводом, если БД повреждена
+func IntegrityCheck(database *sql.DB) error {
+	rows, err := database.Query("PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity_check: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// copyDatabase копирует основную БД соединения src в dst
+func copyDatabase(dst, src *sql.DB) error {
+	ctx := context.Background()
+
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(d interface{}) error {
+		return srcConn.Raw(func(s interface{}) error {
+			dstSQLite, ok := d.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("резервное копирование поддерживается только для SQLite")
+			}
+			srcSQLite, ok := s.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("резервное копирование поддерживается только для SQLite")
+			}
+
+			backup, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return err
+			}
+			for {
+				done, err := backup.Step(backupPagesPerStep)
+				if err != nil {
+					backup.Finish()
+					return err
+				}
+				if done {
+					break
+				}
+				time.Sleep(backupStepPause)
+			}
+			return backup.Finish()
+		})
+	})
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Провайдеры, чьи категории сопоставляются с внутренними (ProviderNewsdata — в usage.go)
+const (
+	ProviderRSS      = "rss"
+	ProviderTelegram = "telegram"
+)
+
+// DefaultCategory — категория статьи, для которой не нашлось ни одного сопоставления
+const DefaultCategory = "top"
+
+// Category — внутренняя категория с отображаемыми названиями
+type Category struct {
+	ID       string            `json:"id"`
+	NameRU   string            `json:"name_ru"`
+	NameEN   string            `json:"name_en"`
+	Position int               `json:"position"`
+	Mappings []CategoryMapping `json:"mappings"`
+}
+
+// CategoryMapping связывает название категории у провайдера с внутренней категорией
+type CategoryMapping struct {
+	Provider   string `json:"provider"`
+	Name       string `json:"name"`
+	CategoryID string `json:"category_id"`
+}
+
+// IsCategoryProvider проверяет, что для провайдера можно задавать сопоставления
+func IsCategoryProvider(provider string) bool {
+	switch provider {
+	case ProviderNewsdata, ProviderRSS, ProviderTelegram:
+		return true
+	}
+	return false
+}
+
+// ListCategories возвращает справочник категорий вместе с сопоставлениями
+func ListCategories(db *sql.DB) ([]Category, error) {
+	rows, err := db.Query("SELECT id, name_ru, name_en, position FROM categories ORDER BY position, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+	index := make(map[string]int)
+	for rows.Next() {
+		c := Category{Mappings: []CategoryMapping{}}
+		if err := rows.Scan(&c.ID, &c.NameRU, &c.NameEN, &c.Position); err != nil {
+			return nil, err
+		}
+		index[c.ID] = len(categories)
+		categories = append(categories, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	mappings, err := db.Query("SELECT provider, name, category_id FROM category_mappings ORDER BY provider, name")
+	if err != nil {
+		return nil, err
+	}
+	defer mappings.Close()
+
+	for mappings.Next() {
+		var m CategoryMapping
+		if err := mappings.Scan(&m.Provider, &m.Name, &m.CategoryID); err != nil {
+			return nil, err
+		}
+		if i, ok := index[m.CategoryID]; ok {
+			categories[i].Mappings = append(categories[i].Mappings, m)
+		}
+	}
+	return categories, mappings.Err()
+}
+
+// CategoryIDs возвращает идентификаторы всех внутренних категорий
+func CategoryIDs(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT id FROM categories ORDER BY position, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CategoryExists проверяет, есть ли внутренняя категория с таким id
+func CategoryExists(db *sql.DB, id string) (bool, error) {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM categories WHERE id = ?", id).Scan(&count); err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// SaveCategory создаёт категорию или обновляет её названия и порядок
+func SaveCategory(db *sql.DB, c Category) error {
+	_, err := exec(db,
+		`INSERT INTO categories (id, name_ru, name_en, position) VALUES (?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			name_ru = excluded.name_ru,
+			name_en = excluded.name_en,
+			position = excluded.position`,
+		c.ID, c.NameRU, c.NameEN, c.Position,
+	)
+	return err
+}
+
+// DeleteCategory удаляет категорию и её сопоставления.
+// Статьи сохраняют id категории, пока их не перезапишет провайдер.
+func DeleteCategory(db *sql.DB, id string) error {
+	return write(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM category_mappings WHERE category_id = ?", id); err != nil {
+			return err
+		}
+		res, err := tx.Exec("DELETE FROM categories WHERE id = ?", id)
+		if err != nil {
+			return err
+		}
+		return expectAffected(res)
+	})
+}
+
+// SetCategoryMapping сопоставляет категорию провайдера с внутренней категорией
+func SetCategoryMapping(db *sql.DB, m CategoryMapping) error {
+	_, err := exec(db,
+		`INSERT INTO category_mappings (provider, name, category_id) VALUES (?, ?, ?)
+		ON CONFLICT(provider, name) DO UPDATE SET category_id = excluded.category_id`,
+		m.Provider, normalizeCategoryName(m.Name), m.CategoryID,
+	)
+	return err
+}
+
+// DeleteCategoryMapping удаляет сопоставление категории провайдера
+func DeleteCategoryMapping(db *sql.DB, provider, name string) error {
+	res, err := exec(db,
+		"DELETE FROM category_mappings WHERE provider = ? AND name = ?",
+		provider, normalizeCategoryName(name),
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(res)
+}
+
+// MapCategories переводит категории провайдера во внутренние.
+// Названия, уже совпадающие с внутренними id, остаются как есть, неизвестные отбрасываются.
+// Если не сопоставилось ничего, статья попадает в DefaultCategory.
+func MapCategories(db *sql.DB, provider string, names []string) ([]string, error) {
+	var mapped []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = normalizeCategoryName(name)
+		if name == "" {
+			continue
+		}
+
+		var id string
+		err := db.QueryRow(
+			`SELECT category_id FROM category_mappings WHERE provider = ? AND name = ?
+			UNION ALL SELECT id FROM categories WHERE id = ?
+			LIMIT 1`,
+			provider, name, name,
+		).Scan(&id)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			mapped = append(mapped, id)
+		}
+	}
+
+	if len(mapped) == 0 {
+		mapped = []string{DefaultCategory}
+	}
+	return mapped, nil
+}
+
+// normalizeCategoryName приводит название категории провайдера к виду, в котором оно хранится
+func normalizeCategoryName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
@@ -2,9 +2,6 @@ package db
 
 import (
 	"database/sql"
-	"newsAPI/collyan"
-	"newsAPI/gemini"
-	_ "newsAPI/gemini"
 	"strings"
 	"time"
 
@@ -13,112 +10,271 @@ import (
 
 const dbFile = "news.db"
 
+// Параметры соединения: WAL позволяет читать во время записи (в том числе скриптам Telegram),
+// busy_timeout ждёт чужую блокировку вместо ошибки "database is locked",
+// а BEGIN IMMEDIATE берёт блокировку записи сразу и не упирается в неё посреди транзакции.
+const dbParams = "?_journal_mode=WAL&_busy_timeout=10000&_txlock=immediate"
+
 type NewsArticle struct {
-	ArticleID   string   `json:"article_id"`
-	Title       string   `json:"title"`
-	Link        string   `json:"link"`
-	Keywords    []string `json:"keywords"`
-	Creator     []string `json:"creator"`
-	VideoURL    string   `json:"video_url"`
-	Description string   `json:"description"`
-	Content     string   `json:"content"`
-	PubDate     string   `json:"publishedAt"`
-	ImageURL    string   `json:"urlToImage"`
-	SourceID    string   `json:"source_id"`
-	SourceName  string   `json:"source_name"`
-	SourceURL   string   `json:"url"`
-	Language    string   `json:"language"`
-	Country     []string `json:"country"`
-	Category    []string `json:"tags"`
-	Sentiment   string   `json:"sentiment"`
+	ArticleID   string    `json:"article_id"`
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Keywords    []string  `json:"keywords"`
+	Creator     []string  `json:"creator"`
+	VideoURL    string    `json:"video_url"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	PubDate     string    `json:"pub_date_original"` // дата публикации в том виде, в каком её прислал источник
+	PublishedAt time.Time `json:"publishedAt"`       // она же в UTC, нулевая — если формат не распознан
+	ImageURL    string    `json:"urlToImage"`
+	SourceID    string    `json:"source_id"`
+	SourceName  string    `json:"source_name"`
+	SourceURL   string    `json:"url"`
+	Language    string    `json:"language"`
+	Country     []string  `json:"country"`
+	Category    []string  `json:"tags"`
+	Sentiment   string    `json:"sentiment"`
+	SourceType  string    `json:"source_type"` // провайдер: newsdata, rss, manual, telegram; пусто — newsdata
+	Pinned      bool      `json:"pinned"`      // закреплена модератором
+	Hidden      bool      `json:"hidden"`      // скрыта модератором
+}
+
+// SourceTypeManual — статьи, добавленные администратором через /admin/articles
+const SourceTypeManual = "manual"
+
+// Open открывает файл БД без проверки схемы (нужно команде migrate)
+func Open() (*sql.DB, error) {
+	return sql.Open("sqlite3", dbFile+dbParams)
 }
 
-// Инициализация БД
+// Инициализация БД: новая пустая база сразу создаётся по всем миграциям,
+// в остальных случаях версия схемы должна совпадать с ожидаемой
 func InitDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", dbFile)
+	db, err := Open()
 	if err != nil {
 		return nil, err
 	}
 
-	// Создаем таблицу новостей
-	createNewsTable := `CREATE TABLE IF NOT EXISTS news (
-		article_id TEXT PRIMARY KEY,
-		title TEXT,
-		link TEXT,
-		keywords TEXT,
-		creator TEXT,
-		video_url TEXT,
-		description TEXT,
-		content TEXT,
-		pub_date TEXT,
-		image_url TEXT,
-		source_id TEXT,
-		source_name TEXT,
-		source_url TEXT,
-		language TEXT,
-		country TEXT,
-		category TEXT,
-		sentiment TEXT
-	);`
-
-	// Создаем таблицу пользователей
-	createUsersTable := `CREATE TABLE IF NOT EXISTS users (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		email TEXT UNIQUE NOT NULL,
-		password TEXT NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);`
-
-	// Создаем таблицу для истории запросов к AI
-	createConversationsTable := `CREATE TABLE IF NOT EXISTS conversations (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		question TEXT NOT NULL,
-		answer TEXT NOT NULL,
-		timestamp INTEGER NOT NULL
-	);`
-
-	// Выполняем создание таблиц
-	_, err = db.Exec(createNewsTable)
+	empty, err := isEmpty(db)
 	if err != nil {
+		db.Close()
 		return nil, err
 	}
-
-	_, err = db.Exec(createUsersTable)
-	if err != nil {
-		return nil, err
+	if empty {
+		if _, err := MigrateUp(db); err != nil {
+			db.Close()
+			return nil, err
+		}
 	}
 
-	_, err = db.Exec(createConversationsTable)
-	if err != nil {
+	if err := CheckSchemaVersion(db); err != nil {
+		db.Close()
 		return nil, err
 	}
-
 	return db, nil
 }
 
 // Сохранение новости в БД
 func SaveToDB(db *sql.DB, article NewsArticle) error {
-	if article.Description == "" {
-		article.Description = collyan.ScrapperCollyan(article.Link)
-		article.Description = gemini.GeminiResponse("Сделай краткое описание в 2-3 предолжения: " + article.Description)
-	}
-	_, err := db.Exec(
-		`INSERT OR IGNORE INTO news (article_id, title, link, keywords, creator, video_url, description, content, pub_date, image_url, source_id, source_name, source_url, language, country, category, sentiment)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
-		article.ArticleID, article.Title, article.Link,
-		strings.Join(article.Keywords, ", "),
-		strings.Join(article.Creator, ", "),
-		article.VideoURL, article.Description, article.Content,
-		article.PubDate, article.ImageURL, article.SourceID,
-		article.SourceName, article.SourceURL, article.Language,
-		strings.Join(article.Country, ", "),
-		strings.Join(article.Category, ", "),
-		article.Sentiment,
-	)
+	_, err := SaveArticle(db, article)
 	return err
 }
 
-func saveToDBAI(db *sql.DB, question, answer string) error {
-	_, err := db.Exec("INSERT INTO conversations (question, answer, timestamp) VALUES (?, ?, ?)", question, answer, time.Now().Unix())
-	return err
+// SaveArticle сохраняет новость и сообщает, была ли она добавлена (false — уже была в БД).
+// Если статья уже есть, категории, ключевые слова и страны объединяются,
+// а непустые поля от провайдера перезаписывают старые значения.
+// Новость без описания сохраняется сразу и ставится в очередь обогащения.
+func SaveArticle(db *sql.DB, article NewsArticle) (bool, error) {
+	var inserted bool
+	err := write(db, func(tx *sql.Tx) error {
+		var err error
+		inserted, err = saveArticleTx(tx, article)
+		return err
+	})
+	return inserted, err
+}
+
+// SaveResult — итог сохранения одной статьи из пачки
+type SaveResult struct {
+	Inserted bool
+	Err      error
+}
+
+// SaveArticles сохраняет пачку статей одной транзакцией с подготовленными запросами.
+// Ошибка одной статьи откатывает только её (через SAVEPOINT), остальные сохраняются.
+func SaveArticles(db *sql.DB, articles []NewsArticle) ([]SaveResult, error) {
+	results := make([]SaveResult, len(articles))
+	err := write(db, func(tx *sql.Tx) error {
+		batch := newBatchTx(tx)
+		defer batch.close()
+
+		for i, article := range articles {
+			if _, err := tx.Exec("SAVEPOINT article"); err != nil {
+				return err
+			}
+			inserted, saveErr := saveArticleTx(batch, article)
+			if saveErr != nil {
+				if _, err := tx.Exec("ROLLBACK TO article"); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.Exec("RELEASE article"); err != nil {
+				return err
+			}
+			results[i] = SaveResult{Inserted: inserted && saveErr == nil, Err: saveErr}
+		}
+		return nil
+	})
+	return results, err
+}
+
+func saveArticleTx(tx sqlTx, article NewsArticle) (bool, error) {
+	now := time.Now().UTC()
+	hasDescription := strings.TrimSpace(article.Description) != ""
+
+	var edited bool
+	err := tx.QueryRow("SELECT edited_at IS NOT NULL FROM news WHERE article_id = ?", article.ArticleID).Scan(&edited)
+
+	if err == sql.ErrNoRows {
+		enrichmentStatus := EnrichmentDone
+		if !hasDescription {
+			enrichmentStatus = EnrichmentPending
+		}
+
+		_, err = tx.Exec(
+			`INSERT INTO news (article_id, title, link, video_url, description, content, pub_date, published_at, image_url, source_id, source_name, source_url, language, sentiment, source_type, enrichment_status, last_seen_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			article.ArticleID, article.Title, article.Link,
+			article.VideoURL, article.Description, article.Content,
+			article.PubDate, publishedAt(article), article.ImageURL, article.SourceID,
+			article.SourceName, article.SourceURL, article.Language,
+			article.Sentiment, sourceType(article), enrichmentStatus, now,
+		)
+		if err != nil {
+			return false, err
+		}
+		if err := mergeArticleListsTx(tx, article); err != nil {
+			return false, err
+		}
+
+		if !hasDescription {
+			if err := enqueueEnrichmentTx(tx, article.ArticleID, now); err != nil {
+				return false, err
+			}
+		}
+		if err := recordRevisionTx(tx, article.ArticleID, now); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	// Поля и категории, которые правил модератор, провайдер не перезаписывает
+	if edited {
+		article.Title, article.Description, article.ImageURL = "", "", ""
+		article.Category = nil
+		hasDescription = false
+	}
+
+	// Статья уже есть: объединяем списки и обновляем то, что провайдер прислал заново
+	_, err = tx.Exec(
+		`UPDATE news SET
+			title = COALESCE(NULLIF(?, ''), title),
+			link = COALESCE(NULLIF(?, ''), link),
+			video_url = COALESCE(NULLIF(?, ''), video_url),
+			content = COALESCE(NULLIF(?, ''), content),
+			pub_date = COALESCE(NULLIF(?, ''), pub_date),
+			published_at = COALESCE(?, published_at),
+			image_url = COALESCE(NULLIF(?, ''), image_url),
+			source_name = COALESCE(NULLIF(?, ''), source_name),
+			source_url = COALESCE(NULLIF(?, ''), source_url),
+			language = COALESCE(NULLIF(?, ''), language),
+			sentiment = COALESCE(NULLIF(?, ''), sentiment),
+			last_seen_at = ?
+		WHERE article_id = ?`,
+		article.Title, article.Link, article.VideoURL,
+		article.Content, article.PubDate, publishedAt(article), article.ImageURL, article.SourceName,
+		article.SourceURL, article.Language, article.Sentiment,
+		now, article.ArticleID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if err := mergeArticleListsTx(tx, article); err != nil {
+		return false, err
+	}
+
+	// Провайдер прислал описание — оно заменяет старое, задача обогащения больше не нужна
+	if hasDescription {
+		if _, err := tx.Exec(
+			"UPDATE news SET description = ?, enrichment_status = ? WHERE article_id = ?",
+			article.Description, EnrichmentDone, article.ArticleID,
+		); err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec("DELETE FROM enrichment_jobs WHERE article_id = ?", article.ArticleID); err != nil {
+			return false, err
+		}
+	}
+
+	if err := recordRevisionTx(tx, article.ArticleID, now); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// publishedAt возвращает время публикации для колонки published_at или NULL, если оно неизвестно
+func publishedAt(article NewsArticle) interface{} {
+	if article.PublishedAt.IsZero() {
+		return nil
+	}
+	return article.PublishedAt.Unix()
+}
+
+// sourceType возвращает тип источника статьи, по умолчанию — newsdata
+func sourceType(article NewsArticle) string {
+	if article.SourceType == "" {
+		return ProviderNewsdata
+	}
+	return article.SourceType
+}
+
+// mergeLists объединяет списки без повторов, сохраняя порядок: сначала старые значения
+func mergeLists(existing, incoming []string) []string {
+	seen := make(map[string]bool, len(existing)+len(incoming))
+	var merged []string
+	for _, list := range [][]string{existing, incoming} {
+		for _, v := range list {
+			v = strings.TrimSpace(v)
+			key := strings.ToLower(v)
+			if v == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, v)
+		}
+	}
+	return merged
+}
+
+// ArticleExists проверяет, есть ли статья с таким article_id в таблице news
+func ArticleExists(db *sql.DB, articleID string) (bool, error) {
+	var exists int
+	err := db.QueryRow("SELECT COUNT(*) FROM news WHERE article_id = ?", articleID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
+}
+
+// ArticleVisible проверяет, есть ли статья с таким article_id и не скрыта ли она модератором
+func ArticleVisible(db *sql.DB, articleID string) (bool, error) {
+	var exists int
+	err := db.QueryRow("SELECT COUNT(*) FROM news WHERE article_id = ? AND hidden_at IS NULL", articleID).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists > 0, nil
 }
@@ -0,0 +1,193 @@
+package db
+
+import (
+	"database/sql"
+	"math"
+	"time"
+)
+
+// Статусы обогащения статьи (описание через скрапинг и Gemini)
+const (
+	EnrichmentPending = "pending"
+	EnrichmentRunning = "running"
+	EnrichmentDone    = "done"
+	EnrichmentFailed  = "failed"
+)
+
+// EnrichmentJob — задача обогащения, взятая в работу
+type EnrichmentJob struct {
+	ArticleID string
+	Link      string
+	SourceID  string
+	Attempts  int
+}
+
+// EnqueueEnrichment ставит статью в очередь обогащения (или перезапускает задачу)
+func EnqueueEnrichment(db *sql.DB, articleID string) error {
+	return write(db, func(tx *sql.Tx) error {
+		return enqueueEnrichmentTx(tx, articleID, time.Now())
+	})
+}
+
+func enqueueEnrichmentTx(tx sqlTx, articleID string, now time.Time) error {
+	_, err := tx.Exec(
+		`INSERT INTO enrichment_jobs (article_id, status, attempts, next_attempt_at, last_error, updated_at)
+		VALUES (?, ?, 0, ?, '', ?)
+		ON CONFLICT(article_id) DO UPDATE SET
+			status = excluded.status, attempts = 0, next_attempt_at = excluded.next_attempt_at,
+			last_error = '', updated_at = excluded.updated_at`,
+		articleID, EnrichmentPending, now.Unix(), now.Unix(),
+	)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE news SET enrichment_status = ? WHERE article_id = ?", EnrichmentPending, articleID)
+	return err
+}
+
+// EnqueueMissingDescriptions ставит в очередь все статьи с пустым описанием
+// или с описанием "error", которое раньше сохранялось при сбое Gemini
+func EnqueueMissingDescriptions(db *sql.DB) (int, error) {
+	rows, err := db.Query("SELECT article_id FROM news WHERE description IS NULL OR TRIM(description) = '' OR description = 'error'")
+	if err != nil {
+		return 0, err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	err = write(db, func(tx *sql.Tx) error {
+		now := time.Now()
+		for _, id := range ids {
+			if err := enqueueEnrichmentTx(tx, id, now); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// ClaimEnrichmentJobs забирает в работу до limit задач, срок которых наступил
+func ClaimEnrichmentJobs(db *sql.DB, limit int) ([]EnrichmentJob, error) {
+	var jobs []EnrichmentJob
+	err := write(db, func(tx *sql.Tx) error {
+		now := time.Now().Unix()
+		rows, err := tx.Query(
+			`SELECT j.article_id, COALESCE(n.link, ''), COALESCE(n.source_id, ''), j.attempts
+			FROM enrichment_jobs j LEFT JOIN news n ON n.article_id = j.article_id
+			WHERE j.status = ? AND j.next_attempt_at <= ?
+			ORDER BY j.next_attempt_at
+			LIMIT ?`,
+			EnrichmentPending, now, limit,
+		)
+		if err != nil {
+			return err
+		}
+
+		jobs = nil
+		for rows.Next() {
+			var j EnrichmentJob
+			if err := rows.Scan(&j.ArticleID, &j.Link, &j.SourceID, &j.Attempts); err != nil {
+				rows.Close()
+				return err
+			}
+			jobs = append(jobs, j)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, j := range jobs {
+			if _, err := tx.Exec(
+				"UPDATE enrichment_jobs SET status = ?, updated_at = ? WHERE article_id = ?",
+				EnrichmentRunning, now, j.ArticleID,
+			); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("UPDATE news SET enrichment_status = ? WHERE article_id = ?", EnrichmentRunning, j.ArticleID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CompleteEnrichment сохраняет описание и удаляет задачу из очереди
+func CompleteEnrichment(db *sql.DB, articleID, description string) error {
+	return write(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(
+			"UPDATE news SET description = ?, enrichment_status = ? WHERE article_id = ?",
+			description, EnrichmentDone, articleID,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.Exec("DELETE FROM enrichment_jobs WHERE article_id = ?", articleID); err != nil {
+			return err
+		}
+		return recordRevisionTx(tx, articleID, time.Now().UTC())
+	})
+}
+
+// FailEnrichment записывает неудачную попытку.
+// Если retryAt нулевое, задача считается окончательно проваленной.
+func FailEnrichment(db *sql.DB, articleID, errText string, retryAt time.Time) error {
+	status := EnrichmentPending
+	if retryAt.IsZero() {
+		status = EnrichmentFailed
+	}
+
+	return write(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(
+			`UPDATE enrichment_jobs SET status = ?, attempts = attempts + 1, next_attempt_at = ?, last_error = ?, updated_at = ?
+			WHERE article_id = ?`,
+			status, retryAt.Unix(), errText, time.Now().Unix(), articleID,
+		); err != nil {
+			return err
+		}
+		_, err := tx.Exec("UPDATE news SET enrichment_status = ? WHERE article_id = ?", status, articleID)
+		return err
+	})
+}
+
+// ResetRunningEnrichment возвращает в очередь задачи, прерванные остановкой процесса:
+// все выполняемые, если before нулевое, иначе только взятые в работу раньше before
+func ResetRunningEnrichment(db *sql.DB, before time.Time) error {
+	var limit int64 = math.MaxInt64
+	if !before.IsZero() {
+		limit = before.Unix()
+	}
+
+	return write(db, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(
+			`UPDATE news SET enrichment_status = ? WHERE article_id IN (
+				SELECT article_id FROM enrichment_jobs WHERE status = ? AND updated_at < ?)`,
+			EnrichmentPending, EnrichmentRunning, limit,
+		); err != nil {
+			return err
+		}
+		_, err := tx.Exec(
+			"UPDATE enrichment_jobs SET status = ? WHERE status = ? AND updated_at < ?",
+			EnrichmentPending, EnrichmentRunning, limit,
+		)
+		return err
+	})
+}
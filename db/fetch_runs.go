@@ -0,0 +1,172 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// FetchRun — запись о запуске загрузки новостей из источника
+type FetchRun struct {
+	ID         int64     `json:"id"`
+	Source     string    `json:"source"`
+	Category   string    `json:"category"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	HTTPStatus int       `json:"http_status"`
+	Received   int       `json:"received"`
+	Inserted   int       `json:"inserted"`
+	Duplicates int       `json:"duplicates"`
+	Failed     int       `json:"failed"` // статьи, которые не удалось сохранить
+	Error      string    `json:"error,omitempty"`
+}
+
+// FetchRunFilter — фильтры для выборки из fetch_runs; пустые поля не учитываются
+type FetchRunFilter struct {
+	Source   string
+	Category string
+	OnlyErr  *bool // true — только с ошибкой, false — только успешные
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// FetchRunSummary — агрегированная статистика запусков по источнику и категории
+type FetchRunSummary struct {
+	Source      string     `json:"source"`
+	Category    string     `json:"category"`
+	Runs        int        `json:"runs"`
+	Errors      int        `json:"errors"`
+	Received    int        `json:"received"`
+	Inserted    int        `json:"inserted"`
+	LastRun     time.Time  `json:"last_run"`
+	LastSuccess *time.Time `json:"last_success"`
+}
+
+// SaveFetchRun сохраняет запись о запуске
+func SaveFetchRun(db *sql.DB, run FetchRun) error {
+	_, err := exec(db,
+		`INSERT INTO fetch_runs (source, category, started_at, finished_at, http_status, received, inserted, duplicates, failed, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.Source, run.Category, run.StartedAt.UTC(), run.FinishedAt.UTC(), run.HTTPStatus,
+		run.Received, run.Inserted, run.Duplicates, run.Failed, run.Error,
+	)
+	return err
+}
+
+// where собирает условие WHERE по фильтру
+func (f FetchRunFilter) where() (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	if f.Source != "" {
+		conds = append(conds, "source = ?")
+		args = append(args, f.Source)
+	}
+	if f.Category != "" {
+		conds = append(conds, "category = ?")
+		args = append(args, f.Category)
+	}
+	if f.OnlyErr != nil {
+		if *f.OnlyErr {
+			conds = append(conds, "error <> ''")
+		} else {
+			conds = append(conds, "error = ''")
+		}
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "started_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "started_at < ?")
+		args = append(args, f.To.UTC())
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+// ListFetchRuns возвращает запуски по фильтру, начиная с самых новых
+func ListFetchRuns(db *sql.DB, f FetchRunFilter) ([]FetchRun, error) {
+	where, args := f.where()
+	args = append(args, f.Limit, f.Offset)
+
+	rows, err := db.Query(
+		"SELECT id, source, category, started_at, finished_at, http_status, received, inserted, duplicates, failed, error FROM fetch_runs"+
+			where+" ORDER BY started_at DESC LIMIT ? OFFSET ?",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []FetchRun{}
+	for rows.Next() {
+		var r FetchRun
+		if err := rows.Scan(&r.ID, &r.Source, &r.Category, &r.StartedAt, &r.FinishedAt, &r.HTTPStatus,
+			&r.Received, &r.Inserted, &r.Duplicates, &r.Failed, &r.Error); err != nil {
+			return nil, err
+		}
+		runs = append(runs, r)
+	}
+	return runs, rows.Err()
+}
+
+// SummarizeFetchRuns считает статистику запусков по источнику и категории
+func SummarizeFetchRuns(db *sql.DB, f FetchRunFilter) ([]FetchRunSummary, error) {
+	where, args := f.where()
+
+	rows, err := db.Query(
+		`SELECT source, category, COUNT(*),
+			SUM(CASE WHEN error <> '' THEN 1 ELSE 0 END),
+			SUM(received), SUM(inserted),
+			MAX(started_at),
+			MAX(CASE WHEN error = '' THEN started_at END)
+		FROM fetch_runs`+where+`
+		GROUP BY source, category
+		ORDER BY source, category`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []FetchRunSummary{}
+	for rows.Next() {
+		var s FetchRunSummary
+		var lastRun string
+		var lastSuccess sql.NullString
+		if err := rows.Scan(&s.Source, &s.Category, &s.Runs, &s.Errors, &s.Received, &s.Inserted,
+			&lastRun, &lastSuccess); err != nil {
+			return nil, err
+		}
+		s.LastRun = parseStoredTime(lastRun)
+		if lastSuccess.Valid {
+			t := parseStoredTime(lastSuccess.String)
+			s.LastSuccess = &t
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+// parseStoredTime разбирает время, сохранённое драйвером sqlite3 в текстовую колонку.
+// Агрегаты (MAX) возвращают строку, а не time.Time.
+func parseStoredTime(value string) time.Time {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999-07:00",
+		"2006-01-02T15:04:05.999999999-07:00",
+		"2006-01-02 15:04:05",
+	} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
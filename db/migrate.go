@@ -0,0 +1,326 @@
+package db
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Миграции лежат в db/migrations (SQLite) и db/postgres (PostgreSQL)
+// и называются NNNN_имя.up.sql / NNNN_имя.down.sql
+//
+//go:embed migrations/*.sql postgres/*.sql
+var migrationFiles embed.FS
+
+// migrationsDir возвращает каталог миграций для драйвера соединения
+func migrationsDir(db *sql.DB) string {
+	if dialectOf(db) == DialectPostgres {
+		return "postgres"
+	}
+	return "migrations"
+}
+
+// dialectOf определяет диалект по драйверу соединения
+func dialectOf(db *sql.DB) string {
+	if _, ok := db.Driver().(*pq.Driver); ok {
+		return DialectPostgres
+	}
+	return DialectSQLite
+}
+
+// Migration — одна версия схемы
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus — миграция и время её применения (nil — не применена)
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+// Migrations возвращает встроенные миграции SQLite по возрастанию версии
+func Migrations() ([]Migration, error) {
+	return loadMigrations("migrations")
+}
+
+func loadMigrations(dir string) ([]Migration, error) {
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("миграция %s: ожидается суффикс .up.sql или .down.sql", name)
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		number, title, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(number)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("миграция %s: имя должно начинаться с номера версии", name)
+		}
+
+		body, err := migrationFiles.ReadFile(path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: title}
+			byVersion[version] = m
+		}
+		if m.Name != title {
+			return nil, fmt.Errorf("версия %d встречается у двух миграций: %s и %s", version, m.Name, title)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("миграция %04d_%s: нужны оба файла, up и down", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	// Номера идут подряд, чтобы версия схемы однозначно задавала набор таблиц
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("пропущена миграция с версией %d", i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// LatestVersion возвращает версию схемы, которую ожидает приложение от этой БД
+func LatestVersion(db *sql.DB) (int, error) {
+	migrations, err := loadMigrations(migrationsDir(db))
+	if err != nil {
+		return 0, err
+	}
+	return len(migrations), nil
+}
+
+// SchemaVersion возвращает номер последней применённой миграции, 0 — ни одной
+func SchemaVersion(db *sql.DB) (int, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return 0, err
+	}
+	var version int
+	err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	return version, err
+}
+
+// CheckSchemaVersion возвращает ошибку, если схема БД не совпадает с ожидаемой
+func CheckSchemaVersion(db *sql.DB) error {
+	latest, err := LatestVersion(db)
+	if err != nil {
+		return err
+	}
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case current < latest:
+		return fmt.Errorf("схема БД версии %d, приложению нужна %d: выполните команду migrate up", current, latest)
+	case current > latest:
+		return fmt.Errorf("схема БД версии %d новее, чем знает приложение (%d)", current, latest)
+	}
+	return nil
+}
+
+// MigrateUp применяет все неприменённые миграции и возвращает их
+func MigrateUp(db *sql.DB) ([]Migration, error) {
+	migrations, err := loadMigrations(migrationsDir(db))
+	if err != nil {
+		return nil, err
+	}
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []Migration
+	for _, m := range migrations {
+		if m.Version <= current {
+			continue
+		}
+		if err := applyMigration(db, m, m.Up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(
+				rebind(dialectOf(db), "INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)"),
+				m.Version, m.Name, time.Now().UTC(),
+			)
+			return err
+		}); err != nil {
+			return applied, err
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// MigrateDown откатывает steps последних применённых миграций и возвращает их.
+// Ниже версии 1 схема не откатывается.
+func MigrateDown(db *sql.DB, steps int) ([]Migration, error) {
+	migrations, err := loadMigrations(migrationsDir(db))
+	if err != nil {
+		return nil, err
+	}
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if current > len(migrations) {
+		return nil, fmt.Errorf("схема БД версии %d новее, чем знает приложение (%d)", current, len(migrations))
+	}
+	// Первая миграция принимает уже существующие таблицы с данными, её откат их бы удалил
+	if current > 0 && current-steps < 1 {
+		return nil, fmt.Errorf("откатить можно не больше %d миграций: первая миграция необратима", current-1)
+	}
+
+	var reverted []Migration
+	for version := current; version > 0 && len(reverted) < steps; version-- {
+		m := migrations[version-1]
+		if err := applyMigration(db, m, m.Down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(rebind(dialectOf(db), "DELETE FROM schema_migrations WHERE version = ?"), m.Version)
+			return err
+		}); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, m)
+	}
+	return reverted, nil
+}
+
+// MigrationStatuses перечисляет все миграции с отметкой о применении
+func MigrationStatuses(db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := loadMigrations(migrationsDir(db))
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		s := MigrationStatus{Version: m.Version, Name: m.Name}
+		if at, ok := applied[m.Version]; ok {
+			s.AppliedAt = &at
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+// applyMigration выполняет SQL миграции и запись в schema_migrations одной транзакцией
+func applyMigration(db *sql.DB, m Migration, query string, record func(*sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(query); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return fmt.Errorf("миграция %04d_%s: %w (соберите приложение с -tags sqlite_fts5)", m.Version, m.Name, err)
+		}
+		return fmt.Errorf("миграция %04d_%s: %w", m.Version, m.Name, err)
+	}
+	if err := record(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	return err
+}
+
+// isEmpty проверяет, что в БД ещё нет ни одной таблицы приложения
+func isEmpty(db *sql.DB) (bool, error) {
+	var count int
+	if dialectOf(db) == DialectPostgres {
+		err := db.QueryRow(
+			`SELECT COUNT(*) FROM information_schema.tables
+			WHERE table_schema = current_schema() AND table_name <> 'schema_migrations'`,
+		).Scan(&count)
+		return count == 0, err
+	}
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master
+		WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')`,
+	).Scan(&count)
+	return count == 0, err
+}
+
+// rebind заменяет плейсхолдеры ? на $1, $2, ... для PostgreSQL
+func rebind(dialect, query string) string {
+	if dialect != DialectPostgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,3 @@
+-- Миграция 0001 необратима: она принимает таблицы, созданные до появления миграций
+-- и скриптами Telegram, и откат удалил бы все статьи и пользователей.
+-- MigrateDown не откатывает схему ниже версии 1.
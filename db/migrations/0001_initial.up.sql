@@ -0,0 +1,56 @@
+-- Исходные таблицы приложения. IF NOT EXISTS — чтобы принять базы,
+-- созданные до появления миграций, и таблицы, созданные скриптами Telegram.
+CREATE TABLE IF NOT EXISTS news (
+	article_id TEXT PRIMARY KEY,
+	title TEXT,
+	link TEXT,
+	keywords TEXT,
+	creator TEXT,
+	video_url TEXT,
+	description TEXT,
+	content TEXT,
+	pub_date TEXT,
+	image_url TEXT,
+	source_id TEXT,
+	source_name TEXT,
+	source_url TEXT,
+	language TEXT,
+	country TEXT,
+	category TEXT,
+	sentiment TEXT
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	email TEXT UNIQUE NOT NULL,
+	password TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS conversations (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	question TEXT NOT NULL,
+	answer TEXT NOT NULL,
+	timestamp INTEGER NOT NULL
+);
+
+-- Таблицы telegram/scripts/database.py
+CREATE TABLE IF NOT EXISTS telegram_channels (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	channel_id INTEGER UNIQUE,
+	channel_username TEXT,
+	channel_title TEXT,
+	last_message_id INTEGER DEFAULT 0,
+	added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	is_active BOOLEAN DEFAULT 1
+);
+
+CREATE TABLE IF NOT EXISTS telegram_messages (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	message_id INTEGER,
+	channel_id INTEGER,
+	message_text TEXT,
+	message_date TIMESTAMP,
+	media_url TEXT,
+	FOREIGN KEY (channel_id) REFERENCES telegram_channels (channel_id)
+);
@@ -0,0 +1,4 @@
+DROP TABLE fetch_runs;
+DROP TABLE api_usage;
+DROP TABLE news_sources;
+ALTER TABLE users DROP COLUMN is_admin;
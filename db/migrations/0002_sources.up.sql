@@ -0,0 +1,34 @@
+-- Администраторы, RSS/Atom источники, учёт расхода API и журнал загрузок
+ALTER TABLE users ADD COLUMN is_admin INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE news_sources (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind TEXT NOT NULL,
+	name TEXT NOT NULL,
+	url TEXT UNIQUE NOT NULL,
+	category TEXT NOT NULL DEFAULT 'top',
+	language TEXT NOT NULL DEFAULT 'ru',
+	enabled INTEGER NOT NULL DEFAULT 1,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE api_usage (
+	provider TEXT NOT NULL,
+	day TEXT NOT NULL,
+	credits INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (provider, day)
+);
+
+CREATE TABLE fetch_runs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	source TEXT NOT NULL,
+	category TEXT NOT NULL DEFAULT '',
+	started_at TIMESTAMP NOT NULL,
+	finished_at TIMESTAMP NOT NULL,
+	http_status INTEGER NOT NULL DEFAULT 0,
+	received INTEGER NOT NULL DEFAULT 0,
+	inserted INTEGER NOT NULL DEFAULT 0,
+	duplicates INTEGER NOT NULL DEFAULT 0,
+	error TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_fetch_runs_category_started ON fetch_runs (category, started_at);
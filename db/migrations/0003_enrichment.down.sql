@@ -0,0 +1,3 @@
+DROP TABLE enrichment_jobs;
+ALTER TABLE news DROP COLUMN last_seen_at;
+ALTER TABLE news DROP COLUMN enrichment_status;
@@ -0,0 +1,13 @@
+-- Очередь обогащения статей (скрапинг + описание от Gemini)
+ALTER TABLE news ADD COLUMN enrichment_status TEXT NOT NULL DEFAULT '';
+ALTER TABLE news ADD COLUMN last_seen_at TIMESTAMP;
+
+CREATE TABLE enrichment_jobs (
+	article_id TEXT PRIMARY KEY,
+	status TEXT NOT NULL,
+	attempts INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at INTEGER NOT NULL,
+	last_error TEXT NOT NULL DEFAULT '',
+	updated_at INTEGER NOT NULL
+);
+CREATE INDEX idx_enrichment_jobs_due ON enrichment_jobs (status, next_attempt_at);
@@ -0,0 +1,3 @@
+DROP TABLE story_bands;
+DROP TABLE article_stories;
+DROP TABLE stories;
@@ -0,0 +1,24 @@
+-- Сюжеты: кластеры похожих статей из разных источников
+CREATE TABLE stories (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	representative_id TEXT NOT NULL,
+	size INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_stories_updated ON stories (updated_at);
+
+CREATE TABLE article_stories (
+	article_id TEXT PRIMARY KEY,
+	story_id INTEGER NOT NULL REFERENCES stories (id),
+	signature BLOB NOT NULL,
+	created_at INTEGER NOT NULL
+);
+CREATE INDEX idx_article_stories_story ON article_stories (story_id);
+
+CREATE TABLE story_bands (
+	band INTEGER NOT NULL,
+	bucket INTEGER NOT NULL,
+	article_id TEXT NOT NULL,
+	PRIMARY KEY (band, bucket, article_id)
+);
@@ -0,0 +1,10 @@
+-- Прогресс исторической загрузки (backfill) по категориям и дням
+CREATE TABLE backfill_progress (
+	category TEXT NOT NULL,
+	day TEXT NOT NULL,
+	next_page TEXT NOT NULL DEFAULT '',
+	pages INTEGER NOT NULL DEFAULT 0,
+	done INTEGER NOT NULL DEFAULT 0,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (category, day)
+);
@@ -0,0 +1,8 @@
+DROP TABLE source_stats;
+ALTER TABLE news_sources DROP COLUMN disabled_reason;
+ALTER TABLE news_sources DROP COLUMN last_item_at;
+ALTER TABLE news_sources DROP COLUMN last_success_at;
+ALTER TABLE news_sources DROP COLUMN last_error;
+ALTER TABLE news_sources DROP COLUMN consecutive_failures;
+ALTER TABLE news_sources DROP COLUMN failures;
+ALTER TABLE news_sources DROP COLUMN runs;
@@ -0,0 +1,16 @@
+-- Здоровье источников: счётчики опросов лент и статистика скрапинга по source_id
+ALTER TABLE news_sources ADD COLUMN runs INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news_sources ADD COLUMN failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news_sources ADD COLUMN consecutive_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE news_sources ADD COLUMN last_error TEXT NOT NULL DEFAULT '';
+ALTER TABLE news_sources ADD COLUMN last_success_at TIMESTAMP;
+ALTER TABLE news_sources ADD COLUMN last_item_at TIMESTAMP;
+ALTER TABLE news_sources ADD COLUMN disabled_reason TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE source_stats (
+	source_id TEXT PRIMARY KEY,
+	scrapes INTEGER NOT NULL DEFAULT 0,
+	scrape_failures INTEGER NOT NULL DEFAULT 0,
+	scrape_ms_total INTEGER NOT NULL DEFAULT 0,
+	last_scrape_at TIMESTAMP
+);
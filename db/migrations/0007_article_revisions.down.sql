@@ -0,0 +1,2 @@
+DROP TABLE article_revisions;
+ALTER TABLE news DROP COLUMN content_hash;
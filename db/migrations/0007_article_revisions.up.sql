@@ -0,0 +1,14 @@
+-- Ревизии статей: каждая версия заголовка, описания и текста
+ALTER TABLE news ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE article_revisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	article_id TEXT NOT NULL,
+	revision INTEGER NOT NULL,
+	content_hash TEXT NOT NULL,
+	title TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	content TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP NOT NULL,
+	UNIQUE (article_id, revision)
+);
@@ -0,0 +1,2 @@
+DROP TABLE category_mappings;
+DROP TABLE categories;
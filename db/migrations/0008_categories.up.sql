@@ -0,0 +1,49 @@
+-- Справочник категорий и сопоставления с категориями провайдеров.
+-- Начальные данные; дальше справочник меняется через API.
+CREATE TABLE categories (
+	id TEXT PRIMARY KEY,
+	name_ru TEXT NOT NULL,
+	name_en TEXT NOT NULL DEFAULT '',
+	position INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE category_mappings (
+	provider TEXT NOT NULL,
+	name TEXT NOT NULL,
+	category_id TEXT NOT NULL REFERENCES categories (id),
+	PRIMARY KEY (provider, name)
+);
+
+INSERT INTO categories (id, name_ru, name_en, position) VALUES
+	('top', 'Главное', 'Top', 0),
+	('politics', 'Политика', 'Politics', 1),
+	('health', 'Здоровье', 'Health', 2),
+	('sports', 'Спорт', 'Sports', 3),
+	('business', 'Бизнес', 'Business', 4),
+	('science', 'Наука', 'Science', 5),
+	('food', 'Еда', 'Food', 6),
+	('technology', 'Технологии', 'Technology', 7),
+	('entertainment', 'Развлечения', 'Entertainment', 8),
+	('world', 'В мире', 'World', 9),
+	('environment', 'Экология', 'Environment', 10);
+
+-- Категории newsdata.io, которых нет у нас, и частые теги RSS
+INSERT INTO category_mappings (provider, name, category_id) VALUES
+	('newsdata', 'crime', 'top'),
+	('newsdata', 'domestic', 'top'),
+	('newsdata', 'other', 'top'),
+	('newsdata', 'education', 'science'),
+	('newsdata', 'lifestyle', 'entertainment'),
+	('newsdata', 'tourism', 'entertainment'),
+	('rss', 'политика', 'politics'),
+	('rss', 'здоровье', 'health'),
+	('rss', 'спорт', 'sports'),
+	('rss', 'экономика', 'business'),
+	('rss', 'бизнес', 'business'),
+	('rss', 'наука', 'science'),
+	('rss', 'технологии', 'technology'),
+	('rss', 'наука и техника', 'technology'),
+	('rss', 'культура', 'entertainment'),
+	('rss', 'мир', 'world'),
+	('rss', 'в мире', 'world'),
+	('rss', 'экология', 'environment');
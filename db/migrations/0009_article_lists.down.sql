@@ -0,0 +1,24 @@
+ALTER TABLE news ADD COLUMN keywords TEXT;
+ALTER TABLE news ADD COLUMN creator TEXT;
+ALTER TABLE news ADD COLUMN country TEXT;
+ALTER TABLE news ADD COLUMN category TEXT;
+
+UPDATE news SET keywords = (
+	SELECT group_concat(value, ', ' ORDER BY position) FROM article_keywords WHERE article_id = news.article_id
+);
+DROP TABLE article_keywords;
+
+UPDATE news SET creator = (
+	SELECT group_concat(value, ', ' ORDER BY position) FROM article_creators WHERE article_id = news.article_id
+);
+DROP TABLE article_creators;
+
+UPDATE news SET country = (
+	SELECT group_concat(value, ', ' ORDER BY position) FROM article_countries WHERE article_id = news.article_id
+);
+DROP TABLE article_countries;
+
+UPDATE news SET category = (
+	SELECT group_concat(value, ', ' ORDER BY position) FROM article_categories WHERE article_id = news.article_id
+);
+DROP TABLE article_categories;
@@ -0,0 +1,76 @@
+-- Списковые поля статьи (ключевые слова, авторы, страны, категории) переезжают
+-- из строк через ", " в отдельные таблицы с индексом по значению.
+-- COLLATE NOCASE: одно и то же значение в разном регистре считается повтором.
+
+CREATE TABLE article_keywords (
+	article_id TEXT NOT NULL,
+	value TEXT NOT NULL COLLATE NOCASE,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (article_id, value)
+);
+CREATE INDEX idx_article_keywords_value ON article_keywords (value, article_id);
+
+WITH RECURSIVE split (article_id, value, rest, position) AS (
+	SELECT article_id, '', COALESCE(keywords, '') || ',', -1 FROM news
+	UNION ALL
+	SELECT article_id, TRIM(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1), position + 1
+	FROM split WHERE rest <> ''
+)
+INSERT OR IGNORE INTO article_keywords (article_id, value, position)
+SELECT article_id, value, position FROM split WHERE value <> '';
+
+CREATE TABLE article_creators (
+	article_id TEXT NOT NULL,
+	value TEXT NOT NULL COLLATE NOCASE,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (article_id, value)
+);
+CREATE INDEX idx_article_creators_value ON article_creators (value, article_id);
+
+WITH RECURSIVE split (article_id, value, rest, position) AS (
+	SELECT article_id, '', COALESCE(creator, '') || ',', -1 FROM news
+	UNION ALL
+	SELECT article_id, TRIM(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1), position + 1
+	FROM split WHERE rest <> ''
+)
+INSERT OR IGNORE INTO article_creators (article_id, value, position)
+SELECT article_id, value, position FROM split WHERE value <> '';
+
+CREATE TABLE article_countries (
+	article_id TEXT NOT NULL,
+	value TEXT NOT NULL COLLATE NOCASE,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (article_id, value)
+);
+CREATE INDEX idx_article_countries_value ON article_countries (value, article_id);
+
+WITH RECURSIVE split (article_id, value, rest, position) AS (
+	SELECT article_id, '', COALESCE(country, '') || ',', -1 FROM news
+	UNION ALL
+	SELECT article_id, TRIM(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1), position + 1
+	FROM split WHERE rest <> ''
+)
+INSERT OR IGNORE INTO article_countries (article_id, value, position)
+SELECT article_id, value, position FROM split WHERE value <> '';
+
+CREATE TABLE article_categories (
+	article_id TEXT NOT NULL,
+	value TEXT NOT NULL COLLATE NOCASE,
+	position INTEGER NOT NULL,
+	PRIMARY KEY (article_id, value)
+);
+CREATE INDEX idx_article_categories_value ON article_categories (value, article_id);
+
+WITH RECURSIVE split (article_id, value, rest, position) AS (
+	SELECT article_id, '', COALESCE(category, '') || ',', -1 FROM news
+	UNION ALL
+	SELECT article_id, TRIM(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1), position + 1
+	FROM split WHERE rest <> ''
+)
+INSERT OR IGNORE INTO article_categories (article_id, value, position)
+SELECT article_id, value, position FROM split WHERE value <> '';
+
+ALTER TABLE news DROP COLUMN keywords;
+ALTER TABLE news DROP COLUMN creator;
+ALTER TABLE news DROP COLUMN country;
+ALTER TABLE news DROP COLUMN category;
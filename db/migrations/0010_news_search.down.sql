@@ -0,0 +1,7 @@
+DROP TRIGGER news_fts_keywords_delete;
+DROP TRIGGER news_fts_keywords_insert;
+DROP TRIGGER news_fts_delete;
+DROP TRIGGER news_fts_update;
+DROP TRIGGER news_fts_insert;
+DROP TABLE news_fts;
+DROP TABLE news_fts_docs;
@@ -0,0 +1,68 @@
+-- Полнотекстовый поиск по заголовку, описанию, тексту и ключевым словам.
+-- Строки news_fts привязаны к статьям через news_fts_docs: у news нет INTEGER PRIMARY KEY,
+-- и её rowid может измениться после VACUUM.
+-- ё заменяется на е, остальное приведение форм слов делает запрос (основа слова + префикс).
+-- Таблица требует сборки с -tags sqlite_fts5.
+CREATE TABLE news_fts_docs (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	article_id TEXT NOT NULL UNIQUE
+);
+
+CREATE VIRTUAL TABLE news_fts USING fts5 (
+	title,
+	description,
+	content,
+	keywords,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+INSERT INTO news_fts_docs (article_id) SELECT article_id FROM news;
+
+INSERT INTO news_fts (rowid, title, description, content, keywords)
+SELECT d.id,
+	replace(replace(COALESCE(n.title, ''), 'ё', 'е'), 'Ё', 'Е'),
+	replace(replace(COALESCE(n.description, ''), 'ё', 'е'), 'Ё', 'Е'),
+	replace(replace(COALESCE(n.content, ''), 'ё', 'е'), 'Ё', 'Е'),
+	replace(replace(COALESCE((SELECT group_concat(value, ' ') FROM article_keywords k WHERE k.article_id = n.article_id), ''), 'ё', 'е'), 'Ё', 'Е')
+FROM news n JOIN news_fts_docs d ON d.article_id = n.article_id;
+
+CREATE TRIGGER news_fts_insert AFTER INSERT ON news BEGIN
+	INSERT INTO news_fts_docs (article_id) VALUES (new.article_id);
+	INSERT INTO news_fts (rowid, title, description, content, keywords)
+	VALUES (
+		(SELECT id FROM news_fts_docs WHERE article_id = new.article_id),
+		replace(replace(COALESCE(new.title, ''), 'ё', 'е'), 'Ё', 'Е'),
+		replace(replace(COALESCE(new.description, ''), 'ё', 'е'), 'Ё', 'Е'),
+		replace(replace(COALESCE(new.content, ''), 'ё', 'е'), 'Ё', 'Е'),
+		''
+	);
+END;
+
+CREATE TRIGGER news_fts_update AFTER UPDATE OF title, description, content ON news
+WHEN old.title IS NOT new.title OR old.description IS NOT new.description OR old.content IS NOT new.content
+BEGIN
+	UPDATE news_fts SET
+		title = replace(replace(COALESCE(new.title, ''), 'ё', 'е'), 'Ё', 'Е'),
+		description = replace(replace(COALESCE(new.description, ''), 'ё', 'е'), 'Ё', 'Е'),
+		content = replace(replace(COALESCE(new.content, ''), 'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = (SELECT id FROM news_fts_docs WHERE article_id = new.article_id);
+END;
+
+CREATE TRIGGER news_fts_delete AFTER DELETE ON news BEGIN
+	DELETE FROM news_fts WHERE rowid = (SELECT id FROM news_fts_docs WHERE article_id = old.article_id);
+	DELETE FROM news_fts_docs WHERE article_id = old.article_id;
+END;
+
+CREATE TRIGGER news_fts_keywords_insert AFTER INSERT ON article_keywords BEGIN
+	UPDATE news_fts SET keywords = replace(replace(
+		(SELECT group_concat(value, ' ') FROM article_keywords WHERE article_id = new.article_id),
+		'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = (SELECT id FROM news_fts_docs WHERE article_id = new.article_id);
+END;
+
+CREATE TRIGGER news_fts_keywords_delete AFTER DELETE ON article_keywords BEGIN
+	UPDATE news_fts SET keywords = replace(replace(
+		COALESCE((SELECT group_concat(value, ' ') FROM article_keywords WHERE article_id = old.article_id), ''),
+		'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = (SELECT id FROM news_fts_docs WHERE article_id = old.article_id);
+END;
@@ -0,0 +1,2 @@
+DROP INDEX idx_news_published_at;
+ALTER TABLE news DROP COLUMN published_at;
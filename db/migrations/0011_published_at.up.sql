@@ -0,0 +1,8 @@
+-- Время публикации в UTC (unix-секунды) для сортировки и фильтров по периоду.
+-- pub_date остаётся исходной строкой от источника.
+-- Сохранённые до сих пор даты уже приведены к виду YYYY-MM-DD HH:MM:SS в UTC.
+ALTER TABLE news ADD COLUMN published_at INTEGER;
+
+UPDATE news SET published_at = unixepoch(pub_date) WHERE pub_date IS NOT NULL AND pub_date <> '';
+
+CREATE INDEX idx_news_published_at ON news (published_at);
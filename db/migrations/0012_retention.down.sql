@@ -0,0 +1,2 @@
+DROP TABLE news_archive;
+ALTER TABLE news DROP COLUMN source_type;
@@ -0,0 +1,29 @@
+-- Тип источника статьи: newsdata, rss, manual, telegram. Нужен для сроков хранения.
+ALTER TABLE news ADD COLUMN source_type TEXT NOT NULL DEFAULT 'newsdata';
+
+UPDATE news SET source_type = 'rss' WHERE article_id LIKE 'rss-%';
+UPDATE news SET source_type = 'manual' WHERE article_id LIKE 'manual-%';
+
+-- Статьи с истёкшим сроком хранения. content сжат gzip,
+-- списковые поля хранятся JSON-массивами.
+CREATE TABLE news_archive (
+	article_id TEXT PRIMARY KEY,
+	title TEXT NOT NULL DEFAULT '',
+	link TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	content_gz BLOB,
+	pub_date TEXT NOT NULL DEFAULT '',
+	published_at INTEGER,
+	image_url TEXT NOT NULL DEFAULT '',
+	source_id TEXT NOT NULL DEFAULT '',
+	source_name TEXT NOT NULL DEFAULT '',
+	source_url TEXT NOT NULL DEFAULT '',
+	language TEXT NOT NULL DEFAULT '',
+	source_type TEXT NOT NULL DEFAULT '',
+	keywords TEXT NOT NULL DEFAULT '[]',
+	creators TEXT NOT NULL DEFAULT '[]',
+	countries TEXT NOT NULL DEFAULT '[]',
+	categories TEXT NOT NULL DEFAULT '[]',
+	archived_at INTEGER NOT NULL
+);
+CREATE INDEX idx_news_archive_published_at ON news_archive (published_at);
@@ -0,0 +1,2 @@
+DROP INDEX idx_conversations_user;
+ALTER TABLE conversations DROP COLUMN user_id;
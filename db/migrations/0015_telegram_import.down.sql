@@ -0,0 +1,3 @@
+DROP INDEX idx_telegram_messages_channel;
+DROP INDEX idx_telegram_messages_pending;
+ALTER TABLE telegram_messages DROP COLUMN imported_at;
@@ -0,0 +1,4 @@
+-- Когда сообщение Telegram перенесено в news; NULL — ещё не перенесено
+ALTER TABLE telegram_messages ADD COLUMN imported_at INTEGER;
+CREATE INDEX idx_telegram_messages_pending ON telegram_messages (id) WHERE imported_at IS NULL;
+CREATE INDEX idx_telegram_messages_channel ON telegram_messages (channel_id, message_date);
@@ -0,0 +1 @@
+ALTER TABLE fetch_runs DROP COLUMN failed;
@@ -0,0 +1,2 @@
+-- Сколько статей запуска не удалось сохранить; они не входят в duplicates
+ALTER TABLE fetch_runs ADD COLUMN failed INTEGER NOT NULL DEFAULT 0;
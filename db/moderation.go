@@ -0,0 +1,247 @@
+package db
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrArticleNotFound — статьи с таким article_id нет
+var ErrArticleNotFound = errors.New("статья не найдена")
+
+// Действия в журнале модерации
+const (
+	ModerationEdit         = "edit"         // изменено поле field
+	ModerationRecategorize = "recategorize" // заменён список категорий
+	ModerationHide         = "hide"
+	ModerationUnhide       = "unhide"
+	ModerationPin          = "pin"
+	ModerationUnpin        = "unpin"
+)
+
+// ArticleEdit — изменения статьи модератором; nil — поле не меняется
+type ArticleEdit struct {
+	Title       *string
+	Description *string
+	ImageURL    *string
+	Categories  *[]string // id категорий из справочника, заменяют текущие
+	Hidden      *bool
+	Pinned      *bool
+}
+
+// ModerationEntry — запись журнала модерации
+type ModerationEntry struct {
+	ID        int64     `json:"id"`
+	ArticleID string    `json:"article_id"`
+	UserID    int64     `json:"user_id"`
+	Action    string    `json:"action"`
+	Field     string    `json:"field,omitempty"`
+	OldValue  string    `json:"old_value,omitempty"`
+	NewValue  string    `json:"new_value,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ModerationFilter — выборка из журнала модерации
+type ModerationFilter struct {
+	ArticleID string // пусто — все статьи
+	Limit     int
+	Offset    int
+}
+
+// changed сообщает, правил ли модератор поле field
+func changed(entries []ModerationEntry, field string) bool {
+	for _, e := range entries {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// moderateArticleTx применяет edit к статье и пишет в moderation_log по записи на каждое
+// действительное изменение. Запросы общие для SQLite и PostgreSQL, время хранится
+// в колонках диалекта (unix-секунды или TIMESTAMPTZ).
+func moderateArticleTx(tx sqlTx, dialect, articleID string, userID int64, edit ArticleEdit, now time.Time) ([]ModerationEntry, error) {
+	q := func(query string) string { return rebind(dialect, query) }
+	ts := interface{}(now.Unix())
+	if dialect == DialectPostgres {
+		ts = now
+	}
+
+	var title, description, imageURL string
+	var hidden, pinned bool
+	err := tx.QueryRow(
+		q(`SELECT COALESCE(title, ''), COALESCE(description, ''), COALESCE(image_url, ''),
+			hidden_at IS NOT NULL, pinned_at IS NOT NULL
+		FROM news WHERE article_id = ?`),
+		articleID,
+	).Scan(&title, &description, &imageURL, &hidden, &pinned)
+	if err == sql.ErrNoRows {
+		return nil, ErrArticleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ModerationEntry
+	edited := false
+
+	for _, f := range []struct {
+		column  string
+		current string
+		value   *string
+	}{
+		{"title", title, edit.Title},
+		{"description", description, edit.Description},
+		{"image_url", imageURL, edit.ImageURL},
+	} {
+		if f.value == nil || *f.value == f.current {
+			continue
+		}
+		if _, err := tx.Exec(q("UPDATE news SET "+f.column+" = ? WHERE article_id = ?"), *f.value, articleID); err != nil {
+			return nil, err
+		}
+		entries = append(entries, ModerationEntry{Action: ModerationEdit, Field: f.column, OldValue: f.current, NewValue: *f.value})
+		edited = true
+	}
+
+	if edit.Categories != nil {
+		current, err := articleCategoriesTx(tx, dialect, articleID)
+		if err != nil {
+			return nil, err
+		}
+		categories := mergeLists(nil, *edit.Categories)
+		if strings.Join(current, listSeparator) != strings.Join(categories, listSeparator) {
+			if _, err := tx.Exec(q("DELETE FROM article_categories WHERE article_id = ?"), articleID); err != nil {
+				return nil, err
+			}
+			for i, category := range categories {
+				if _, err := tx.Exec(
+					q("INSERT INTO article_categories (article_id, value, position) VALUES (?, ?, ?)"),
+					articleID, category, i,
+				); err != nil {
+					return nil, err
+				}
+			}
+			oldJSON, _ := json.Marshal(nonNil(current))
+			newJSON, _ := json.Marshal(nonNil(categories))
+			entries = append(entries, ModerationEntry{
+				Action: ModerationRecategorize, Field: "categories",
+				OldValue: string(oldJSON), NewValue: string(newJSON),
+			})
+			edited = true
+		}
+	}
+
+	if edited {
+		if _, err := tx.Exec(q("UPDATE news SET edited_at = ? WHERE article_id = ?"), ts, articleID); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range []struct {
+		column     string
+		current    bool
+		value      *bool
+		set, unset string
+	}{
+		{"hidden_at", hidden, edit.Hidden, ModerationHide, ModerationUnhide},
+		{"pinned_at", pinned, edit.Pinned, ModerationPin, ModerationUnpin},
+	} {
+		if f.value == nil || *f.value == f.current {
+			continue
+		}
+		var value interface{}
+		action := f.unset
+		if *f.value {
+			value, action = ts, f.set
+		}
+		if _, err := tx.Exec(q("UPDATE news SET "+f.column+" = ? WHERE article_id = ?"), value, articleID); err != nil {
+			return nil, err
+		}
+		entries = append(entries, ModerationEntry{Action: action})
+	}
+
+	for i := range entries {
+		e := &entries[i]
+		e.ArticleID, e.UserID, e.CreatedAt = articleID, userID, now.UTC().Truncate(time.Second)
+		err := tx.QueryRow(
+			q(`INSERT INTO moderation_log (article_id, user_id, action, field, old_value, new_value, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`),
+			e.ArticleID, e.UserID, e.Action, e.Field, e.OldValue, e.NewValue, ts,
+		).Scan(&e.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return nonNilEntries(entries), nil
+}
+
+func articleCategoriesTx(tx sqlTx, dialect, articleID string) ([]string, error) {
+	rows, err := tx.Query(rebind(dialect, "SELECT value FROM article_categories WHERE article_id = ? ORDER BY position"), articleID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// moderationLog читает журнал модерации, начиная с последних записей
+func moderationLog(db *sql.DB, dialect string, filter ModerationFilter) ([]ModerationEntry, error) {
+	createdAt := "created_at"
+	if dialect == DialectPostgres {
+		createdAt = "EXTRACT(EPOCH FROM created_at)::BIGINT"
+	}
+
+	query := "SELECT id, article_id, user_id, action, field, old_value, new_value, " + createdAt + " FROM moderation_log"
+	var args []interface{}
+	if filter.ArticleID != "" {
+		query += " WHERE article_id = ?"
+		args = append(args, filter.ArticleID)
+	}
+	query += " ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := db.Query(rebind(dialect, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []ModerationEntry{}
+	for rows.Next() {
+		var e ModerationEntry
+		var created int64
+		if err := rows.Scan(&e.ID, &e.ArticleID, &e.UserID, &e.Action, &e.Field, &e.OldValue, &e.NewValue, &created); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = time.Unix(created, 0).UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func nonNilEntries(entries []ModerationEntry) []ModerationEntry {
+	if entries == nil {
+		return []ModerationEntry{}
+	}
+	return entries
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS conversations;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS article_categories;
+DROP TABLE IF EXISTS article_countries;
+DROP TABLE IF EXISTS article_creators;
+DROP TABLE IF EXISTS article_keywords;
+DROP TABLE IF EXISTS news;
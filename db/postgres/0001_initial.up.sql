@@ -0,0 +1,79 @@
+-- Схема хранилища статей, пользователей и разговоров в PostgreSQL.
+-- Остальные таблицы (источники, очереди, сюжеты, журналы) остаются в локальной SQLite.
+CREATE TABLE news (
+	article_id TEXT PRIMARY KEY,
+	title TEXT NOT NULL DEFAULT '',
+	link TEXT NOT NULL DEFAULT '',
+	video_url TEXT NOT NULL DEFAULT '',
+	description TEXT NOT NULL DEFAULT '',
+	content TEXT NOT NULL DEFAULT '',
+	pub_date TEXT NOT NULL DEFAULT '',
+	published_at TIMESTAMPTZ,
+	image_url TEXT NOT NULL DEFAULT '',
+	source_id TEXT NOT NULL DEFAULT '',
+	source_name TEXT NOT NULL DEFAULT '',
+	source_url TEXT NOT NULL DEFAULT '',
+	language TEXT NOT NULL DEFAULT '',
+	sentiment TEXT NOT NULL DEFAULT '',
+	last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	-- Ключевые слова через пробел: по ним строится поисковый вектор
+	keywords_text TEXT NOT NULL DEFAULT '',
+	-- Заголовок и ключевые слова весят больше описания, описание — больше текста
+	search tsvector GENERATED ALWAYS AS (
+		setweight(to_tsvector('russian'::regconfig, title), 'A') ||
+		setweight(to_tsvector('russian'::regconfig, keywords_text), 'A') ||
+		setweight(to_tsvector('russian'::regconfig, description), 'B') ||
+		setweight(to_tsvector('russian'::regconfig, content), 'C')
+	) STORED
+);
+
+CREATE INDEX idx_news_published_at ON news (published_at);
+CREATE INDEX idx_news_search ON news USING GIN (search);
+
+-- Списковые поля статьи; значения сравниваются без учёта регистра, как COLLATE NOCASE в SQLite
+CREATE TABLE article_keywords (
+	article_id TEXT NOT NULL REFERENCES news (article_id) ON DELETE CASCADE,
+	value TEXT NOT NULL,
+	position INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX idx_article_keywords_pk ON article_keywords (article_id, lower(value));
+CREATE INDEX idx_article_keywords_value ON article_keywords (lower(value));
+
+CREATE TABLE article_creators (
+	article_id TEXT NOT NULL REFERENCES news (article_id) ON DELETE CASCADE,
+	value TEXT NOT NULL,
+	position INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX idx_article_creators_pk ON article_creators (article_id, lower(value));
+CREATE INDEX idx_article_creators_value ON article_creators (lower(value));
+
+CREATE TABLE article_countries (
+	article_id TEXT NOT NULL REFERENCES news (article_id) ON DELETE CASCADE,
+	value TEXT NOT NULL,
+	position INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX idx_article_countries_pk ON article_countries (article_id, lower(value));
+CREATE INDEX idx_article_countries_value ON article_countries (lower(value));
+
+CREATE TABLE article_categories (
+	article_id TEXT NOT NULL REFERENCES news (article_id) ON DELETE CASCADE,
+	value TEXT NOT NULL,
+	position INTEGER NOT NULL
+);
+CREATE UNIQUE INDEX idx_article_categories_pk ON article_categories (article_id, lower(value));
+CREATE INDEX idx_article_categories_value ON article_categories (lower(value));
+
+CREATE TABLE users (
+	id BIGSERIAL PRIMARY KEY,
+	email TEXT UNIQUE NOT NULL,
+	password TEXT NOT NULL,
+	is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE conversations (
+	id BIGSERIAL PRIMARY KEY,
+	question TEXT NOT NULL,
+	answer TEXT NOT NULL,
+	timestamp BIGINT NOT NULL
+);
@@ -0,0 +1,5 @@
+-- Тип источника статьи: newsdata, rss, manual, telegram
+ALTER TABLE news ADD COLUMN source_type TEXT NOT NULL DEFAULT 'newsdata';
+
+UPDATE news SET source_type = 'rss' WHERE article_id LIKE 'rss-%';
+UPDATE news SET source_type = 'manual' WHERE article_id LIKE 'manual-%';
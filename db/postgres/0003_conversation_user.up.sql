@@ -0,0 +1,3 @@
+-- Автор вопроса к помощнику; NULL — вопрос задан без токена
+ALTER TABLE conversations ADD COLUMN user_id BIGINT REFERENCES users(id) ON DELETE CASCADE;
+CREATE INDEX idx_conversations_user ON conversations (user_id, timestamp);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS moderation_log;
+ALTER TABLE news DROP COLUMN edited_at;
+ALTER TABLE news DROP COLUMN pinned_at;
+ALTER TABLE news DROP COLUMN hidden_at;
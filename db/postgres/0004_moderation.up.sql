@@ -0,0 +1,19 @@
+-- Модерация статей: hidden_at — скрыта (мягкое удаление), pinned_at — закреплена в начале ленты,
+-- edited_at — поля или категории правил модератор, провайдер их больше не перезаписывает
+ALTER TABLE news ADD COLUMN hidden_at TIMESTAMPTZ;
+ALTER TABLE news ADD COLUMN pinned_at TIMESTAMPTZ;
+ALTER TABLE news ADD COLUMN edited_at TIMESTAMPTZ;
+
+-- Журнал действий модераторов. old_value и new_value — строки, категории — JSON-массивы.
+CREATE TABLE moderation_log (
+	id BIGSERIAL PRIMARY KEY,
+	article_id TEXT NOT NULL,
+	user_id BIGINT NOT NULL REFERENCES users(id),
+	action TEXT NOT NULL,
+	field TEXT NOT NULL DEFAULT '',
+	old_value TEXT NOT NULL DEFAULT '',
+	new_value TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_moderation_log_article ON moderation_log (article_id, created_at);
+CREATE INDEX idx_moderation_log_created_at ON moderation_log (created_at);
@@ -0,0 +1,448 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresRepository — хранилище статей, пользователей и разговоров в PostgreSQL
+type PostgresRepository struct {
+	DB *sql.DB
+
+	lockConn *sql.Conn // соединение, которое держит блокировку фоновых загрузок
+}
+
+// Ключ advisory-блокировки фоновых загрузок; одинаковый у всех экземпляров
+const backgroundLockKey = 4602750316
+
+// LockBackgroundJobs делает процесс единственным экземпляром, который загружает статьи в эту базу.
+// Квота newsdata.io и журналы загрузок ведутся в news.db каждого экземпляра, поэтому загрузки
+// с нескольких экземпляров тратили бы квоту многократно. Блокировка держится до Close;
+// false — её уже держит другой экземпляр.
+func (r *PostgresRepository) LockBackgroundJobs() (bool, error) {
+	ctx := context.Background()
+	conn, err := r.DB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", backgroundLockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !locked {
+		conn.Close()
+		return false, nil
+	}
+	r.lockConn = conn
+	return true, nil
+}
+
+// OpenPostgresDB подключается к PostgreSQL без проверки схемы (нужно команде migrate).
+// Переменные окружения в DSN раскрываются.
+func OpenPostgresDB(dsn string) (*sql.DB, error) {
+	database, err := sql.Open("postgres", os.ExpandEnv(dsn))
+	if err != nil {
+		return nil, err
+	}
+	if err := database.Ping(); err != nil {
+		database.Close()
+		return nil, err
+	}
+	return database, nil
+}
+
+// OpenPostgres подключается к PostgreSQL по DSN. Пустая база сразу создаётся
+// по миграциям из db/postgres, иначе версия схемы должна совпадать.
+func OpenPostgres(dsn string) (*PostgresRepository, error) {
+	database, err := OpenPostgresDB(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	empty, err := isEmpty(database)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	if empty {
+		if _, err := MigrateUp(database); err != nil {
+			database.Close()
+			return nil, err
+		}
+	}
+
+	if err := CheckSchemaVersion(database); err != nil {
+		database.Close()
+		return nil, err
+	}
+	return &PostgresRepository{DB: database}, nil
+}
+
+func (r *PostgresRepository) Dialect() string {
+	return DialectPostgres
+}
+
+// SaveArticle сохраняет статью по тем же правилам, что и SaveArticle для SQLite:
+// непустые поля от провайдера перезаписывают старые, списки объединяются
+func (r *PostgresRepository) SaveArticle(article NewsArticle) (bool, error) {
+	var inserted bool
+	err := runTx(r.DB, func(tx *sql.Tx) error {
+		var err error
+		inserted, err = r.saveArticleTx(tx, article)
+		return err
+	})
+	return inserted, err
+}
+
+// SaveArticles сохраняет пачку одной транзакцией; ошибка статьи откатывает только её SAVEPOINT
+func (r *PostgresRepository) SaveArticles(articles []NewsArticle) ([]SaveResult, error) {
+	results := make([]SaveResult, len(articles))
+	err := runTx(r.DB, func(tx *sql.Tx) error {
+		batch := newBatchTx(tx)
+		defer batch.close()
+
+		for i, article := range articles {
+			if _, err := tx.Exec("SAVEPOINT article"); err != nil {
+				return err
+			}
+			inserted, saveErr := r.saveArticleTx(batch, article)
+			if saveErr != nil {
+				if _, err := tx.Exec("ROLLBACK TO SAVEPOINT article"); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.Exec("RELEASE SAVEPOINT article"); err != nil {
+				return err
+			}
+			results[i] = SaveResult{Inserted: inserted && saveErr == nil, Err: saveErr}
+		}
+		return nil
+	})
+	return results, err
+}
+
+func (r *PostgresRepository) saveArticleTx(tx sqlTx, article NewsArticle) (bool, error) {
+	var published interface{}
+	if !article.PublishedAt.IsZero() {
+		published = article.PublishedAt.UTC()
+	}
+
+	// xmax = 0 только у строки, которую вставили, а не обновили
+	var inserted, edited bool
+	err := tx.QueryRow(
+		`INSERT INTO news (article_id, title, link, video_url, description, content, pub_date, published_at, image_url, source_id, source_name, source_url, language, sentiment, source_type, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, now())
+		ON CONFLICT (article_id) DO UPDATE SET
+			title = CASE WHEN news.edited_at IS NULL THEN COALESCE(NULLIF(EXCLUDED.title, ''), news.title) ELSE news.title END,
+			link = COALESCE(NULLIF(EXCLUDED.link, ''), news.link),
+			video_url = COALESCE(NULLIF(EXCLUDED.video_url, ''), news.video_url),
+			description = CASE WHEN news.edited_at IS NULL THEN COALESCE(NULLIF(EXCLUDED.description, ''), news.description) ELSE news.description END,
+			content = COALESCE(NULLIF(EXCLUDED.content, ''), news.content),
+			pub_date = COALESCE(NULLIF(EXCLUDED.pub_date, ''), news.pub_date),
+			published_at = COALESCE(EXCLUDED.published_at, news.published_at),
+			image_url = CASE WHEN news.edited_at IS NULL THEN COALESCE(NULLIF(EXCLUDED.image_url, ''), news.image_url) ELSE news.image_url END,
+			source_name = COALESCE(NULLIF(EXCLUDED.source_name, ''), news.source_name),
+			source_url = COALESCE(NULLIF(EXCLUDED.source_url, ''), news.source_url),
+			language = COALESCE(NULLIF(EXCLUDED.language, ''), news.language),
+			sentiment = COALESCE(NULLIF(EXCLUDED.sentiment, ''), news.sentiment),
+			last_seen_at = now()
+		RETURNING xmax = 0, edited_at IS NOT NULL`,
+		article.ArticleID, article.Title, article.Link, article.VideoURL, article.Description,
+		article.Content, article.PubDate, published, article.ImageURL, article.SourceID,
+		article.SourceName, article.SourceURL, article.Language, article.Sentiment, sourceType(article),
+	).Scan(&inserted, &edited)
+	if err != nil {
+		return false, err
+	}
+	// Категории, которые правил модератор, провайдер не дополняет
+	if edited {
+		article.Category = nil
+	}
+
+	if len(article.Creator) > 0 {
+		if _, err := tx.Exec("DELETE FROM article_creators WHERE article_id = $1", article.ArticleID); err != nil {
+			return false, err
+		}
+	}
+	for table, values := range map[string][]string{
+		"article_keywords":   article.Keywords,
+		"article_creators":   article.Creator,
+		"article_countries":  article.Country,
+		"article_categories": article.Category,
+	} {
+		if err := r.appendValuesTx(tx, table, article.ArticleID, values); err != nil {
+			return false, err
+		}
+	}
+
+	// Ключевые слова попадают в поисковый вектор через keywords_text
+	if _, err := tx.Exec(
+		`UPDATE news SET keywords_text = COALESCE(
+			(SELECT string_agg(value, ' ' ORDER BY position) FROM article_keywords WHERE article_id = $1), '')
+		WHERE article_id = $1`,
+		article.ArticleID,
+	); err != nil {
+		return false, err
+	}
+
+	return inserted, nil
+}
+
+// appendValuesTx добавляет в конец списка статьи значения, которых там ещё нет
+func (r *PostgresRepository) appendValuesTx(tx sqlTx, table, articleID string, values []string) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query("SELECT value FROM "+table+" WHERE article_id = $1 ORDER BY position", articleID)
+	if err != nil {
+		return err
+	}
+	var existing []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			rows.Close()
+			return err
+		}
+		existing = append(existing, v)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	merged := mergeLists(existing, values)
+	for i := len(existing); i < len(merged); i++ {
+		if _, err := tx.Exec(
+			"INSERT INTO "+table+" (article_id, value, position) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+			articleID, merged[i], i,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ArticleExists(articleID string) (bool, error) {
+	var exists bool
+	err := r.DB.QueryRow("SELECT EXISTS (SELECT 1 FROM news WHERE article_id = $1)", articleID).Scan(&exists)
+	return exists, err
+}
+
+func (r *PostgresRepository) ListArticles(filter ArticleFilter) ([]NewsArticle, error) {
+	where, args := filter.postgresWhere()
+	query := "SELECT " + postgresArticleColumns + " FROM news"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY news.pinned_at DESC NULLS LAST, news.published_at DESC NULLS LAST LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.DB.Query(rebind(DialectPostgres, query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanArticles(rows)
+}
+
+// SearchArticles ищет по запросу в синтаксисе websearch_to_tsquery:
+// фразы в кавычках, OR, исключение слов через минус
+func (r *PostgresRepository) SearchArticles(query string, filter ArticleFilter) ([]SearchHit, error) {
+	if strings.TrimSpace(query) == "" {
+		return []SearchHit{}, nil
+	}
+
+	where, args := filter.postgresWhere()
+
+	q := "SELECT " + postgresArticleColumns + `,
+			ts_headline('russian', news.title, tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			ts_headline('russian', news.description || ' ' || news.content, tsq,
+				'StartSel=<mark>, StopSel=</mark>, MaxFragments=1, MaxWords=24, MinWords=8, FragmentDelimiter=…')
+		FROM news, websearch_to_tsquery('russian', ?) tsq
+		WHERE news.search @@ tsq`
+	args = append([]interface{}{query}, args...)
+	for _, cond := range where {
+		q += " AND " + cond
+	}
+	q += " ORDER BY ts_rank(news.search, tsq) DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.DB.Query(rebind(DialectPostgres, q), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []SearchHit{}
+	for rows.Next() {
+		var h SearchHit
+		if h.Article, err = scanArticle(rows, &h.TitleHighlight, &h.Snippet); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+func (r *PostgresRepository) CountArticleValues(dimension string, limit int) ([]ValueCount, error) {
+	table, ok := ArticleListTables[dimension]
+	if !ok {
+		return nil, ErrUnknownDimension
+	}
+
+	rows, err := r.DB.Query(
+		`SELECT min(l.value), COUNT(*) AS n FROM `+table+` l
+		JOIN news ON news.article_id = l.article_id AND news.hidden_at IS NULL
+		GROUP BY lower(l.value) ORDER BY n DESC, min(l.value) LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := []ValueCount{}
+	for rows.Next() {
+		var c ValueCount
+		if err := rows.Scan(&c.Value, &c.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+	return counts, rows.Err()
+}
+
+func (r *PostgresRepository) CreateUser(email, passwordHash string) (int64, error) {
+	var id int64
+	err := r.DB.QueryRow(
+		"INSERT INTO users (email, password) VALUES ($1, $2) ON CONFLICT (email) DO NOTHING RETURNING id",
+		email, passwordHash,
+	).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrUserExists
+	}
+	return id, err
+}
+
+func (r *PostgresRepository) GetUserByEmail(email string) (User, error) {
+	u := User{Email: email}
+	err := r.DB.QueryRow("SELECT id, password, is_admin FROM users WHERE email = $1", email).
+		Scan(&u.ID, &u.PasswordHash, &u.IsAdmin)
+	return u, err
+}
+
+func (r *PostgresRepository) IsAdmin(userID int64) (bool, error) {
+	var isAdmin bool
+	err := r.DB.QueryRow("SELECT is_admin FROM users WHERE id = $1", userID).Scan(&isAdmin)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return isAdmin, err
+}
+
+func (r *PostgresRepository) SaveConversation(userID int64, question, answer string) error {
+	_, err := r.DB.Exec(
+		"INSERT INTO conversations (user_id, question, answer, timestamp) VALUES ($1, $2, $3, $4)",
+		nullUserID(userID), question, answer, time.Now().Unix(),
+	)
+	return err
+}
+
+func (r *PostgresRepository) ListConversations(userID int64, limit, offset int) ([]Conversation, int, error) {
+	var total int
+	if err := r.DB.QueryRow("SELECT COUNT(*) FROM conversations WHERE user_id = $1", userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.Query(
+		"SELECT id, question, answer, timestamp FROM conversations WHERE user_id = $1 ORDER BY timestamp DESC, id DESC LIMIT $2 OFFSET $3",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	conversations, err := scanConversations(rows)
+	return conversations, total, err
+}
+
+func (r *PostgresRepository) DeleteConversations(userID int64) (int64, error) {
+	result, err := r.DB.Exec("DELETE FROM conversations WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (r *PostgresRepository) ModerateArticle(articleID string, userID int64, edit ArticleEdit) ([]ModerationEntry, error) {
+	var entries []ModerationEntry
+	err := runTx(r.DB, func(tx *sql.Tx) error {
+		var err error
+		entries, err = moderateArticleTx(tx, DialectPostgres, articleID, userID, edit, time.Now().UTC())
+		return err
+	})
+	return entries, err
+}
+
+func (r *PostgresRepository) ModerationLog(filter ModerationFilter) ([]ModerationEntry, error) {
+	return moderationLog(r.DB, DialectPostgres, filter)
+}
+
+func (r *PostgresRepository) Close() error {
+	if r.lockConn != nil {
+		r.lockConn.Close()
+	}
+	return r.DB.Close()
+}
+
+// postgresWhere строит условия WHERE по таблице news с плейсхолдерами ?, которые затем заменяет rebind
+func (f ArticleFilter) postgresWhere() ([]string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	for dimension, value := range f.lists() {
+		conds = append(conds, "news.article_id IN (SELECT article_id FROM "+ArticleListTables[dimension]+" WHERE lower(value) = lower(?))")
+		args = append(args, value)
+	}
+	conds = append(conds, f.hiddenCond())
+
+	if f.Channel != "" {
+		conds = append(conds, "news.source_type = '"+ProviderTelegram+"' AND lower(news.source_id) = lower(?)")
+		args = append(args, f.Channel)
+	}
+
+	if !f.From.IsZero() {
+		conds = append(conds, "news.published_at >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "news.published_at < ?")
+		args = append(args, f.To.UTC())
+	}
+	return conds, args
+}
+
+// Колонки в порядке, который ожидает scanArticle
+var postgresArticleColumns = "news.article_id, news.title, news.link, " +
+	postgresListColumn("keywords") + ", " + postgresListColumn("creators") + ", " +
+	"news.video_url, news.description, news.content, " +
+	"EXTRACT(EPOCH FROM news.published_at)::BIGINT, news.pub_date, news.image_url, news.source_id, " +
+	"news.source_name, news.source_url, news.language, " +
+	postgresListColumn("countries") + ", " + postgresListColumn("categories") + ", news.sentiment, " +
+	"news.pinned_at IS NOT NULL, news.hidden_at IS NOT NULL"
+
+func postgresListColumn(dimension string) string {
+	return "COALESCE((SELECT string_agg(value, chr(31) ORDER BY position) FROM " +
+		ArticleListTables[dimension] + " WHERE article_id = news.article_id), '')"
+}
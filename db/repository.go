@@ -0,0 +1,118 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Диалекты хранилища
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+)
+
+// ErrUserExists — пользователь с таким email уже зарегистрирован
+var ErrUserExists = errors.New("пользователь уже существует")
+
+// Repository — хранилище статей, пользователей и разговоров с AI.
+// Остальные данные (очереди, сюжеты, источники, журналы) пока живут только в локальной SQLite.
+type Repository interface {
+	// Dialect возвращает DialectSQLite или DialectPostgres
+	Dialect() string
+
+	// SaveArticle сохраняет статью и сообщает, была ли она добавлена (false — уже была)
+	SaveArticle(article NewsArticle) (bool, error)
+	// SaveArticles сохраняет пачку статей одной транзакцией; ошибка одной статьи не мешает остальным
+	SaveArticles(articles []NewsArticle) ([]SaveResult, error)
+	// ArticleExists проверяет, есть ли статья с таким article_id
+	ArticleExists(articleID string) (bool, error)
+	// ListArticles возвращает статьи по фильтру, начиная с самых свежих
+	ListArticles(filter ArticleFilter) ([]NewsArticle, error)
+	// SearchArticles ищет статьи по тексту и возвращает их по убыванию релевантности
+	SearchArticles(query string, filter ArticleFilter) ([]SearchHit, error)
+	// CountArticleValues считает статьи по значениям измерения (keywords, creators, countries, categories)
+	CountArticleValues(dimension string, limit int) ([]ValueCount, error)
+
+	// CreateUser регистрирует пользователя и возвращает его id
+	CreateUser(email, passwordHash string) (int64, error)
+	// GetUserByEmail возвращает пользователя или sql.ErrNoRows
+	GetUserByEmail(email string) (User, error)
+	// IsAdmin проверяет, что пользователь — администратор
+	IsAdmin(userID int64) (bool, error)
+
+	// ModerateArticle применяет правки модератора userID и возвращает записанные в журнал изменения.
+	// Если статьи нет — ErrArticleNotFound.
+	ModerateArticle(articleID string, userID int64, edit ArticleEdit) ([]ModerationEntry, error)
+	// ModerationLog возвращает журнал модерации, начиная с последних записей
+	ModerationLog(filter ModerationFilter) ([]ModerationEntry, error)
+
+	// SaveConversation сохраняет вопрос к AI и ответ; userID 0 — вопрос без авторизации
+	SaveConversation(userID int64, question, answer string) error
+	// ListConversations возвращает разговоры пользователя, начиная с последних, и их общее число
+	ListConversations(userID int64, limit, offset int) ([]Conversation, int, error)
+	// DeleteConversations удаляет всю историю пользователя и возвращает число удалённых разговоров
+	DeleteConversations(userID int64) (int64, error)
+
+	Close() error
+}
+
+// ArticleFilter — фильтры выборки статей
+type ArticleFilter struct {
+	Category string    // id категории из справочника
+	Keyword  string    // точное ключевое слово
+	Country  string    // точная страна
+	Creator  string    // точный автор
+	Channel  string    // канал Telegram: имя без @ или id, только статьи source_type = telegram
+	From     time.Time // начало периода публикации, включительно
+	To       time.Time // конец периода публикации, не включительно
+	Hidden   bool      // true — только скрытые модератором статьи, иначе только видимые
+	Limit    int
+	Offset   int
+}
+
+// SearchHit — найденная статья с подсвеченными совпадениями
+type SearchHit struct {
+	Article        NewsArticle
+	TitleHighlight string
+	Snippet        string
+}
+
+// User — зарегистрированный пользователь
+type User struct {
+	ID           int64
+	Email        string
+	PasswordHash string
+	IsAdmin      bool
+}
+
+// Conversation — вопрос к помощнику и его ответ
+type Conversation struct {
+	ID        int64     `json:"id"`
+	Question  string    `json:"question"`
+	Answer    string    `json:"answer"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// nullUserID возвращает user_id для записи или NULL для анонимного вопроса
+func nullUserID(userID int64) interface{} {
+	if userID == 0 {
+		return nil
+	}
+	return userID
+}
+
+// OpenRepository выбирает хранилище по DSN: postgres:// или postgresql:// — PostgreSQL,
+// иначе используется уже открытая локальная SQLite.
+func OpenRepository(dsn string, sqlite *sql.DB) (Repository, error) {
+	if IsPostgresDSN(dsn) {
+		return OpenPostgres(dsn)
+	}
+	return &SQLiteRepository{DB: sqlite}, nil
+}
+
+// IsPostgresDSN проверяет, что DSN указывает на PostgreSQL
+func IsPostgresDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://")
+}
@@ -0,0 +1,276 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+)
+
+// retentionBatch — сколько статей удаляется одной транзакцией
+const retentionBatch = 200
+
+// RetentionCandidate — статья, которую можно удалить по сроку хранения
+type RetentionCandidate struct {
+	ArticleID   string
+	SourceType  string
+	PublishedAt time.Time
+	Categories  []string
+}
+
+// TelegramMessage — сообщение из telegram_messages с путём к медиафайлу
+type TelegramMessage struct {
+	ID       int64
+	MediaURL string
+}
+
+// ArticlesPublishedBefore возвращает статьи, опубликованные раньше before.
+// Статьи без распознанной даты публикации не возвращаются.
+func ArticlesPublishedBefore(db *sql.DB, before time.Time) ([]RetentionCandidate, error) {
+	rows, err := db.Query(
+		`SELECT news.article_id, news.source_type, news.published_at, `+listColumn("categories")+`
+		FROM news WHERE news.published_at < ? ORDER BY news.published_at`,
+		before.Unix(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []RetentionCandidate
+	for rows.Next() {
+		var c RetentionCandidate
+		var published int64
+		var categories string
+		if err := rows.Scan(&c.ArticleID, &c.SourceType, &published, &categories); err != nil {
+			return nil, err
+		}
+		c.PublishedAt = time.Unix(published, 0).UTC()
+		c.Categories = splitListColumn(categories)
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// ArchiveArticles переносит статьи в news_archive (content сжимается gzip) и удаляет их из news
+func ArchiveArticles(db *sql.DB, articleIDs []string) error {
+	return inBatches(db, articleIDs, func(tx *sql.Tx, batch []string) error {
+		now := time.Now().Unix()
+		for _, id := range batch {
+			if err := archiveArticleTx(tx, id, now); err != nil {
+				return err
+			}
+		}
+		return removeArticlesTx(tx, batch)
+	})
+}
+
+// DeleteArticles удаляет статьи вместе со списками, ревизиями, задачами обогащения и привязкой к сюжетам
+func DeleteArticles(db *sql.DB, articleIDs []string) error {
+	return inBatches(db, articleIDs, removeArticlesTx)
+}
+
+func inBatches(db *sql.DB, ids []string, fn func(*sql.Tx, []string) error) error {
+	for start := 0; start < len(ids); start += retentionBatch {
+		end := start + retentionBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch := ids[start:end]
+		if err := write(db, func(tx *sql.Tx) error { return fn(tx, batch) }); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func archiveArticleTx(tx *sql.Tx, articleID string, archivedAt int64) error {
+	var title, link, description, content, pubDate, imageURL, sourceID, sourceName, sourceURL, language, sourceType string
+	var published sql.NullInt64
+	err := tx.QueryRow(
+		`SELECT COALESCE(title, ''), COALESCE(link, ''), COALESCE(description, ''), COALESCE(content, ''),
+			COALESCE(pub_date, ''), published_at, COALESCE(image_url, ''), COALESCE(source_id, ''),
+			COALESCE(source_name, ''), COALESCE(source_url, ''), COALESCE(language, ''), source_type
+		FROM news WHERE article_id = ?`,
+		articleID,
+	).Scan(&title, &link, &description, &content, &pubDate, &published, &imageURL, &sourceID, &sourceName, &sourceURL, &language, &sourceType)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	compressed, err := gzipString(content)
+	if err != nil {
+		return err
+	}
+
+	lists := make(map[string]string, len(ArticleListTables))
+	for dimension, table := range ArticleListTables {
+		values, err := articleValuesTx(tx, table, articleID)
+		if err != nil {
+			return err
+		}
+		if values == nil {
+			values = []string{}
+		}
+		encoded, err := json.Marshal(values)
+		if err != nil {
+			return err
+		}
+		lists[dimension] = string(encoded)
+	}
+
+	_, err = tx.Exec(
+		`INSERT OR REPLACE INTO news_archive (article_id, title, link, description, content_gz, pub_date, published_at,
+			image_url, source_id, source_name, source_url, language, source_type, keywords, creators, countries, categories, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		articleID, title, link, description, compressed, pubDate, published,
+		imageURL, sourceID, sourceName, sourceURL, language, sourceType,
+		lists["keywords"], lists["creators"], lists["countries"], lists["categories"], archivedAt,
+	)
+	return err
+}
+
+func gzipString(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// removeArticlesTx удаляет статьи и всё, что на них ссылается.
+// Сюжеты пересчитываются: пустые удаляются, у остальных обновляется размер и при необходимости представитель.
+func removeArticlesTx(tx *sql.Tx, articleIDs []string) error {
+	if len(articleIDs) == 0 {
+		return nil
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(articleIDs)), ", ")
+	args := make([]interface{}, len(articleIDs))
+	for i, id := range articleIDs {
+		args[i] = id
+	}
+
+	if _, err := tx.Exec(
+		`CREATE TEMP TABLE IF NOT EXISTS retention_stories (id INTEGER PRIMARY KEY)`,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM retention_stories"); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO retention_stories (id) SELECT story_id FROM article_stories WHERE article_id IN ("+placeholders+")",
+		args...,
+	); err != nil {
+		return err
+	}
+
+	tables := []string{"article_stories", "story_bands", "article_revisions", "enrichment_jobs"}
+	for _, table := range ArticleListTables {
+		tables = append(tables, table)
+	}
+	tables = append(tables, "news")
+	for _, table := range tables {
+		if _, err := tx.Exec("DELETE FROM "+table+" WHERE article_id IN ("+placeholders+")", args...); err != nil {
+			return err
+		}
+	}
+
+	for _, query := range []string{
+		`DELETE FROM stories WHERE id IN (SELECT id FROM retention_stories)
+			AND NOT EXISTS (SELECT 1 FROM article_stories WHERE story_id = stories.id)`,
+		`UPDATE stories SET size = (SELECT COUNT(*) FROM article_stories WHERE story_id = stories.id)
+			WHERE id IN (SELECT id FROM retention_stories)`,
+		`UPDATE stories SET representative_id = (
+				SELECT s.article_id FROM article_stories s WHERE s.story_id = stories.id ORDER BY s.created_at LIMIT 1)
+			WHERE id IN (SELECT id FROM retention_stories)
+				AND representative_id NOT IN (SELECT article_id FROM article_stories WHERE story_id = stories.id)`,
+	} {
+		if _, err := tx.Exec(query); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TelegramMessagesBefore возвращает сообщения Telegram, отправленные раньше before
+func TelegramMessagesBefore(db *sql.DB, before time.Time) ([]TelegramMessage, error) {
+	rows, err := db.Query(
+		"SELECT id, COALESCE(media_url, '') FROM telegram_messages WHERE unixepoch(message_date) < ?",
+		before.Unix(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []TelegramMessage
+	for rows.Next() {
+		var m TelegramMessage
+		if err := rows.Scan(&m.ID, &m.MediaURL); err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
+
+// DeleteTelegramMessages удаляет сообщения Telegram по id
+func DeleteTelegramMessages(db *sql.DB, ids []int64) error {
+	return write(db, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare("DELETE FROM telegram_messages WHERE id = ?")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for _, id := range ids {
+			if _, err := stmt.Exec(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// TelegramMediaNames возвращает имена медиафайлов, на которые ссылаются сообщения Telegram
+func TelegramMediaNames(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("SELECT media_url FROM telegram_messages WHERE media_url IS NOT NULL AND media_url <> ''")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := make(map[string]bool)
+	for rows.Next() {
+		var mediaURL string
+		if err := rows.Scan(&mediaURL); err != nil {
+			return nil, err
+		}
+		names[path.Base(mediaURL)] = true
+	}
+	return names, rows.Err()
+}
+
+// Vacuum обновляет статистику планировщика запросов и возвращает освободившееся место файлу БД
+func Vacuum(db *sql.DB) error {
+	if _, err := db.Exec("ANALYZE"); err != nil {
+		return err
+	}
+	_, err := db.Exec("VACUUM")
+	return err
+}
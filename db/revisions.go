@@ -0,0 +1,106 @@
+package db
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+)
+
+// ArticleRevision — одна сохранённая версия статьи
+type ArticleRevision struct {
+	Revision    int       `json:"revision"`
+	ContentHash string    `json:"content_hash"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FieldChange — изменение одного поля между соседними ревизиями
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ContentHash считает хеш заголовка, описания и текста статьи
+func ContentHash(title, description, content string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + description + "\x00" + content))
+	return hex.EncodeToString(sum[:])
+}
+
+// recordRevisionTx сохраняет новую ревизию, если заголовок, описание или текст
+// статьи изменились с прошлого раза. Вызывается после каждой записи в news.
+func recordRevisionTx(tx sqlTx, articleID string, now time.Time) error {
+	var title, description, content sql.NullString
+	var storedHash string
+	err := tx.QueryRow(
+		"SELECT title, description, content, content_hash FROM news WHERE article_id = ?",
+		articleID,
+	).Scan(&title, &description, &content, &storedHash)
+	if err != nil {
+		return err
+	}
+
+	hash := ContentHash(title.String, description.String, content.String)
+	if hash == storedHash {
+		return nil
+	}
+
+	var last int
+	if err := tx.QueryRow(
+		"SELECT COALESCE(MAX(revision), 0) FROM article_revisions WHERE article_id = ?",
+		articleID,
+	).Scan(&last); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`INSERT INTO article_revisions (article_id, revision, content_hash, title, description, content, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		articleID, last+1, hash, title.String, description.String, content.String, now,
+	); err != nil {
+		return err
+	}
+	_, err = tx.Exec("UPDATE news SET content_hash = ? WHERE article_id = ?", hash, articleID)
+	return err
+}
+
+// ListRevisions возвращает ревизии статьи от первой к последней
+func ListRevisions(db *sql.DB, articleID string) ([]ArticleRevision, error) {
+	rows, err := db.Query(
+		`SELECT revision, content_hash, title, description, content, created_at
+		FROM article_revisions WHERE article_id = ? ORDER BY revision`,
+		articleID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []ArticleRevision{}
+	for rows.Next() {
+		var r ArticleRevision
+		if err := rows.Scan(&r.Revision, &r.ContentHash, &r.Title, &r.Description, &r.Content, &r.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// DiffRevisions перечисляет поля, которые отличаются между двумя ревизиями
+func DiffRevisions(prev, next ArticleRevision) []FieldChange {
+	changes := []FieldChange{}
+	for _, f := range []struct{ name, old, new string }{
+		{"title", prev.Title, next.Title},
+		{"description", prev.Description, next.Description},
+		{"content", prev.Content, next.Content},
+	} {
+		if f.old != f.new {
+			changes = append(changes, FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"newsAPI/stem"
+	"strings"
+	"unicode"
+)
+
+// SearchMatch переводит поисковый запрос пользователя в выражение MATCH для news_fts.
+// Фраза в кавычках ищется как есть, слово со звёздочкой — как префикс,
+// остальные слова — по основе, чтобы находились все их формы.
+// Возвращает false, если в запросе нет ни одного слова.
+func SearchMatch(q string) (string, bool) {
+	q = strings.NewReplacer("ё", "е", "Ё", "Е").Replace(q)
+
+	var terms []string
+	for len(q) > 0 {
+		q = strings.TrimLeftFunc(q, unicode.IsSpace)
+		if q == "" {
+			break
+		}
+
+		if q[0] == '"' {
+			phrase := q[1:]
+			end := strings.IndexByte(phrase, '"')
+			if end < 0 {
+				end = len(phrase)
+				q = ""
+			} else {
+				q = phrase[end+1:]
+			}
+			if words := searchWords(phrase[:end]); len(words) > 0 {
+				terms = append(terms, quoteTerm(strings.Join(words, " ")))
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(q, unicode.IsSpace)
+		if end < 0 {
+			end = len(q)
+		}
+		token := q[:end]
+		q = q[end:]
+
+		prefix := strings.HasSuffix(token, "*")
+		for _, word := range searchWords(token) {
+			switch {
+			case prefix:
+				terms = append(terms, quoteTerm(word)+"*")
+			case isCyrillic(word):
+				terms = append(terms, quoteTerm(stem.Russian(word))+"*")
+			default:
+				terms = append(terms, quoteTerm(word))
+			}
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", false
+	}
+	return strings.Join(terms, " "), true
+}
+
+// searchWords разбивает текст на слова так же, как токенизатор unicode61
+func searchWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func quoteTerm(term string) string {
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+}
+
+func isCyrillic(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
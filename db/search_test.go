@@ -0,0 +1,28 @@
+package db
+
+import "testing"
+
+func TestSearchMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+		ok    bool
+	}{
+		{"выборы президента", `"выбор"* "президент"*`, true},
+		{"ёлка", `"елк"*`, true},
+		{`"новости спорта"`, `"новости спорта"`, true}, // фраза ищется как есть, без стемминга
+		{`"незакрытая фраза`, `"незакрытая фраза"`, true},
+		{"эконом*", `"эконом"*`, true},
+		{"Bitcoin ETF", `"bitcoin" "etf"`, true}, // латиница не стеммится
+		{"covid-19", `"covid" "19"`, true},
+		{`цены"`, `"цен"*`, true},
+		{"", "", false},
+		{"  !!  ", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := SearchMatch(tt.query)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("SearchMatch(%q) = %q, %v, ожидалось %q, %v", tt.query, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+)
+
+// SourceHealth — статистика по source_id статей
+type SourceHealth struct {
+	SourceID              string     `json:"source_id"`
+	SourceName            string     `json:"source_name"`
+	Articles              int        `json:"articles"`
+	LastPublishedAt       *time.Time `json:"last_published_at"`
+	LastSeenAt            *time.Time `json:"last_seen_at"`
+	EmptyDescriptionShare float64    `json:"empty_description_share"`
+	Scrapes               int        `json:"scrapes"`
+	ScrapeErrorRate       float64    `json:"scrape_error_rate"`
+	AvgScrapeMs           float64    `json:"avg_scrape_ms"`
+}
+
+// RecordScrape учитывает одну попытку скрапинга статьи источника
+func RecordScrape(db *sql.DB, sourceID string, latency time.Duration, ok bool) error {
+	failed := 0
+	if !ok {
+		failed = 1
+	}
+	_, err := exec(db,
+		`INSERT INTO source_stats (source_id, scrapes, scrape_failures, scrape_ms_total, last_scrape_at)
+		VALUES (?, 1, ?, ?, ?)
+		ON CONFLICT(source_id) DO UPDATE SET
+			scrapes = scrapes + 1,
+			scrape_failures = scrape_failures + excluded.scrape_failures,
+			scrape_ms_total = scrape_ms_total + excluded.scrape_ms_total,
+			last_scrape_at = excluded.last_scrape_at`,
+		sourceID, failed, latency.Milliseconds(), time.Now().UTC(),
+	)
+	return err
+}
+
+// ListSourceHealth считает статистику по всем source_id, начиная с источников
+// с наибольшей долей пустых описаний
+func ListSourceHealth(db *sql.DB) ([]SourceHealth, error) {
+	rows, err := db.Query(
+		`SELECT COALESCE(n.source_id, ''), COALESCE(MAX(n.source_name), ''), COUNT(*),
+			MAX(n.published_at), MAX(n.last_seen_at),
+			SUM(CASE WHEN n.description IS NULL OR TRIM(n.description) = '' OR n.description = 'error' THEN 1 ELSE 0 END),
+			COALESCE(s.scrapes, 0), COALESCE(s.scrape_failures, 0), COALESCE(s.scrape_ms_total, 0)
+		FROM news n LEFT JOIN source_stats s ON s.source_id = n.source_id
+		GROUP BY n.source_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	health := []SourceHealth{}
+	for rows.Next() {
+		var h SourceHealth
+		var lastPublished sql.NullInt64
+		var lastSeen sql.NullString
+		var empty, failures, msTotal int
+		if err := rows.Scan(&h.SourceID, &h.SourceName, &h.Articles, &lastPublished, &lastSeen,
+			&empty, &h.Scrapes, &failures, &msTotal); err != nil {
+			return nil, err
+		}
+		if lastPublished.Valid {
+			t := time.Unix(lastPublished.Int64, 0).UTC()
+			h.LastPublishedAt = &t
+		}
+		if lastSeen.Valid {
+			t := parseStoredTime(lastSeen.String)
+			h.LastSeenAt = &t
+		}
+		if h.Articles > 0 {
+			h.EmptyDescriptionShare = float64(empty) / float64(h.Articles)
+		}
+		if h.Scrapes > 0 {
+			h.ScrapeErrorRate = float64(failures) / float64(h.Scrapes)
+			h.AvgScrapeMs = float64(msTotal) / float64(h.Scrapes)
+		}
+		health = append(health, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(health, func(i, j int) bool {
+		return health[i].EmptyDescriptionShare > health[j].EmptyDescriptionShare
+	})
+	return health, nil
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Типы источников, которые умеет опрашивать парсер
+const (
+	SourceKindRSS = "rss" // RSS 2.0 или Atom лента
+)
+
+// FeedSource — строка таблицы news_sources вместе со статистикой опросов
+type FeedSource struct {
+	ID        int64     `json:"id"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Category  string    `json:"category"`
+	Language  string    `json:"language"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Runs                int        `json:"runs"`
+	Failures            int        `json:"failures"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastSuccessAt       *time.Time `json:"last_success_at"`
+	LastItemAt          *time.Time `json:"last_item_at"` // последний опрос, принёсший новую статью
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	ErrorRate           float64    `json:"error_rate"` // доля неудачных опросов
+}
+
+const selectSourceColumns = `SELECT id, kind, name, url, category, language, enabled, created_at,
+	runs, failures, consecutive_failures, last_error, last_success_at, last_item_at, disabled_reason
+	FROM news_sources`
+
+// ListSources возвращает все источники из таблицы news_sources
+func ListSources(db *sql.DB) ([]FeedSource, error) {
+	return querySources(db, selectSourceColumns+" ORDER BY id")
+}
+
+// GetEnabledSources возвращает только включённые источники
+func GetEnabledSources(db *sql.DB) ([]FeedSource, error) {
+	return querySources(db, selectSourceColumns+" WHERE enabled = 1 ORDER BY id")
+}
+
+func querySources(db *sql.DB, query string, args ...interface{}) ([]FeedSource, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sources []FeedSource
+	for rows.Next() {
+		var s FeedSource
+		var lastSuccess, lastItem sql.NullTime
+		if err := rows.Scan(&s.ID, &s.Kind, &s.Name, &s.URL, &s.Category, &s.Language, &s.Enabled, &s.CreatedAt,
+			&s.Runs, &s.Failures, &s.ConsecutiveFailures, &s.LastError, &lastSuccess, &lastItem, &s.DisabledReason); err != nil {
+			return nil, err
+		}
+		if lastSuccess.Valid {
+			s.LastSuccessAt = &lastSuccess.Time
+		}
+		if lastItem.Valid {
+			s.LastItemAt = &lastItem.Time
+		}
+		if s.Runs > 0 {
+			s.ErrorRate = float64(s.Failures) / float64(s.Runs)
+		}
+		sources = append(sources, s)
+	}
+	return sources, rows.Err()
+}
+
+// AddSource добавляет новый источник и возвращает его ID
+func AddSource(db *sql.DB, s FeedSource) (int64, error) {
+	result, err := exec(db,
+		"INSERT INTO news_sources (kind, name, url, category, language, enabled) VALUES (?, ?, ?, ?, ?, ?)",
+		s.Kind, s.Name, s.URL, s.Category, s.Language, s.Enabled,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// SetSourceEnabled включает или выключает источник.
+// При включении сбрасывается счётчик ошибок подряд и причина автоотключения.
+func SetSourceEnabled(db *sql.DB, id int64, enabled bool) error {
+	result, err := exec(db,
+		`UPDATE news_sources SET enabled = ?,
+			consecutive_failures = CASE WHEN ? THEN 0 ELSE consecutive_failures END,
+			disabled_reason = CASE WHEN ? THEN '' ELSE disabled_reason END
+		WHERE id = ?`,
+		enabled, enabled, enabled, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// RecordSourceRun обновляет статистику источника после опроса.
+// Пустая лента — успешный опрос без новых статей; ошибкой считаются только сбои загрузки
+// и разбора. После maxConsecutive ошибок подряд источник выключается (0 — не выключать).
+// Возвращает true, если источник был выключен.
+func RecordSourceRun(db *sql.DB, id int64, run FetchRun, runErr error, maxConsecutive int) (bool, error) {
+	now := time.Now().UTC()
+
+	if runErr == nil {
+		// last_item_at — когда из ленты в последний раз пришла новая статья
+		_, err := exec(db,
+			`UPDATE news_sources SET runs = runs + 1, consecutive_failures = 0, last_error = '',
+				last_success_at = ?, last_item_at = CASE WHEN ? THEN ? ELSE last_item_at END
+			WHERE id = ?`,
+			now, run.Inserted > 0, now, id,
+		)
+		return false, err
+	}
+
+	errText := runErr.Error()
+
+	var disabled bool
+	err := write(db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE news_sources SET runs = runs + 1, failures = failures + 1,
+				consecutive_failures = consecutive_failures + 1, last_error = ?
+			WHERE id = ?`,
+			errText, id,
+		)
+		if err != nil || maxConsecutive <= 0 {
+			return err
+		}
+
+		result, err := tx.Exec(
+			`UPDATE news_sources SET enabled = 0, disabled_reason = ?
+			WHERE id = ? AND enabled = 1 AND consecutive_failures >= ?`,
+			"выключен автоматически после ошибок подряд: "+errText, id, maxConsecutive,
+		)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		disabled = n > 0
+		return err
+	})
+	return disabled, err
+}
+
+// DeleteSource удаляет источник
+func DeleteSource(db *sql.DB, id int64) error {
+	result, err := exec(db, "DELETE FROM news_sources WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// expectAffected возвращает sql.ErrNoRows, если запрос не затронул ни одной строки
+func expectAffected(result sql.Result) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// SQLiteRepository — хранилище в локальной SQLite (news.db)
+type SQLiteRepository struct {
+	DB *sql.DB
+}
+
+func (r *SQLiteRepository) Dialect() string {
+	return DialectSQLite
+}
+
+func (r *SQLiteRepository) SaveArticle(article NewsArticle) (bool, error) {
+	return SaveArticle(r.DB, article)
+}
+
+func (r *SQLiteRepository) SaveArticles(articles []NewsArticle) ([]SaveResult, error) {
+	return SaveArticles(r.DB, articles)
+}
+
+func (r *SQLiteRepository) ArticleExists(articleID string) (bool, error) {
+	return ArticleExists(r.DB, articleID)
+}
+
+func (r *SQLiteRepository) ListArticles(filter ArticleFilter) ([]NewsArticle, error) {
+	where, args := filter.sqliteWhere()
+	query := "SELECT " + articleColumns + " FROM news"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY news.pinned_at IS NULL, news.pinned_at DESC, news.published_at DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanArticles(rows)
+}
+
+func (r *SQLiteRepository) SearchArticles(query string, filter ArticleFilter) ([]SearchHit, error) {
+	match, ok := SearchMatch(query)
+	if !ok {
+		return []SearchHit{}, nil
+	}
+
+	where, args := filter.sqliteWhere()
+
+	// Заголовок и ключевые слова весят больше описания, описание — больше текста
+	q := "SELECT " + articleColumns + `,
+			highlight(news_fts, 0, '<mark>', '</mark>'),
+			snippet(news_fts, -1, '<mark>', '</mark>', '…', 24)
+		FROM news_fts
+		JOIN news_fts_docs d ON d.id = news_fts.rowid
+		JOIN news ON news.article_id = d.article_id
+		WHERE news_fts MATCH ?`
+	args = append([]interface{}{match}, args...)
+	for _, cond := range where {
+		q += " AND " + cond
+	}
+	q += " ORDER BY bm25(news_fts, 10.0, 4.0, 1.0, 6.0) LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := r.DB.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []SearchHit{}
+	for rows.Next() {
+		var h SearchHit
+		if h.Article, err = scanArticle(rows, &h.TitleHighlight, &h.Snippet); err != nil {
+			return nil, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
+
+func (r *SQLiteRepository) CountArticleValues(dimension string, limit int) ([]ValueCount, error) {
+	return CountArticleValues(r.DB, dimension, limit)
+}
+
+func (r *SQLiteRepository) CreateUser(email, passwordHash string) (int64, error) {
+	var id int64
+	err := write(r.DB, func(tx *sql.Tx) error {
+		var exists int
+		if err := tx.QueryRow("SELECT COUNT(*) FROM users WHERE email = ?", email).Scan(&exists); err != nil {
+			return err
+		}
+		if exists > 0 {
+			return ErrUserExists
+		}
+
+		result, err := tx.Exec("INSERT INTO users (email, password) VALUES (?, ?)", email, passwordHash)
+		if err != nil {
+			return err
+		}
+		id, err = result.LastInsertId()
+		return err
+	})
+	return id, err
+}
+
+func (r *SQLiteRepository) GetUserByEmail(email string) (User, error) {
+	u := User{Email: email}
+	err := r.DB.QueryRow("SELECT id, password, is_admin FROM users WHERE email = ?", email).
+		Scan(&u.ID, &u.PasswordHash, &u.IsAdmin)
+	return u, err
+}
+
+func (r *SQLiteRepository) IsAdmin(userID int64) (bool, error) {
+	var isAdmin bool
+	err := r.DB.QueryRow("SELECT is_admin FROM users WHERE id = ?", userID).Scan(&isAdmin)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return isAdmin, err
+}
+
+func (r *SQLiteRepository) SaveConversation(userID int64, question, answer string) error {
+	_, err := exec(r.DB,
+		"INSERT INTO conversations (user_id, question, answer, timestamp) VALUES (?, ?, ?, ?)",
+		nullUserID(userID), question, answer, time.Now().Unix(),
+	)
+	return err
+}
+
+func (r *SQLiteRepository) ListConversations(userID int64, limit, offset int) ([]Conversation, int, error) {
+	var total int
+	if err := r.DB.QueryRow("SELECT COUNT(*) FROM conversations WHERE user_id = ?", userID).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.DB.Query(
+		"SELECT id, question, answer, timestamp FROM conversations WHERE user_id = ? ORDER BY timestamp DESC, id DESC LIMIT ? OFFSET ?",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	conversations, err := scanConversations(rows)
+	return conversations, total, err
+}
+
+func (r *SQLiteRepository) DeleteConversations(userID int64) (int64, error) {
+	result, err := exec(r.DB, "DELETE FROM conversations WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// scanConversations читает строки id, question, answer, timestamp
+func scanConversations(rows *sql.Rows) ([]Conversation, error) {
+	conversations := []Conversation{}
+	for rows.Next() {
+		var conv Conversation
+		var timestamp int64
+		if err := rows.Scan(&conv.ID, &conv.Question, &conv.Answer, &timestamp); err != nil {
+			return nil, err
+		}
+		conv.CreatedAt = time.Unix(timestamp, 0).UTC()
+		conversations = append(conversations, conv)
+	}
+	return conversations, rows.Err()
+}
+
+func (r *SQLiteRepository) ModerateArticle(articleID string, userID int64, edit ArticleEdit) ([]ModerationEntry, error) {
+	var entries []ModerationEntry
+	err := write(r.DB, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		var err error
+		if entries, err = moderateArticleTx(tx, DialectSQLite, articleID, userID, edit, now); err != nil {
+			return err
+		}
+
+		// Описание от модератора заменяет результат обогащения
+		if changed(entries, "description") {
+			if _, err := tx.Exec(
+				"UPDATE news SET enrichment_status = ? WHERE article_id = ?",
+				EnrichmentDone, articleID,
+			); err != nil {
+				return err
+			}
+			if _, err := tx.Exec("DELETE FROM enrichment_jobs WHERE article_id = ?", articleID); err != nil {
+				return err
+			}
+		}
+		if changed(entries, "title") || changed(entries, "description") {
+			return recordRevisionTx(tx, articleID, now)
+		}
+		return nil
+	})
+	return entries, err
+}
+
+func (r *SQLiteRepository) ModerationLog(filter ModerationFilter) ([]ModerationEntry, error) {
+	return moderationLog(r.DB, DialectSQLite, filter)
+}
+
+// Close ничего не делает: локальную SQLite закрывает тот, кто её открыл
+func (r *SQLiteRepository) Close() error {
+	return nil
+}
+
+// lists возвращает непустые фильтры по спискам статьи, ключ — измерение из ArticleListTables
+func (f ArticleFilter) lists() map[string]string {
+	lists := make(map[string]string)
+	for dimension, value := range map[string]string{
+		"categories": f.Category,
+		"keywords":   f.Keyword,
+		"countries":  f.Country,
+		"creators":   f.Creator,
+	} {
+		if value != "" {
+			lists[dimension] = value
+		}
+	}
+	return lists
+}
+
+// sqliteWhere строит условия WHERE по таблице news
+func (f ArticleFilter) sqliteWhere() ([]string, []interface{}) {
+	var conds []string
+	var args []interface{}
+
+	for dimension, value := range f.lists() {
+		conds = append(conds, "news.article_id IN (SELECT article_id FROM "+ArticleListTables[dimension]+" WHERE value = ?)")
+		args = append(args, value)
+	}
+	conds = append(conds, f.hiddenCond())
+
+	if f.Channel != "" {
+		conds = append(conds, "news.source_type = '"+ProviderTelegram+"' AND lower(news.source_id) = lower(?)")
+		args = append(args, f.Channel)
+	}
+
+	if !f.From.IsZero() {
+		conds = append(conds, "news.published_at >= ?")
+		args = append(args, f.From.Unix())
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "news.published_at < ?")
+		args = append(args, f.To.Unix())
+	}
+	return conds, args
+}
+
+// hiddenCond оставляет только видимые статьи или, для модерации, только скрытые
+func (f ArticleFilter) hiddenCond() string {
+	if f.Hidden {
+		return "news.hidden_at IS NOT NULL"
+	}
+	return "news.hidden_at IS NULL"
+}
+
+// Колонки таблицы news в порядке, который ожидает scanArticle
+var articleColumns = "news.article_id, COALESCE(news.title, ''), COALESCE(news.link, ''), " +
+	listColumn("keywords") + ", " + listColumn("creators") + ", " +
+	"COALESCE(news.video_url, ''), COALESCE(news.description, ''), COALESCE(news.content, ''), " +
+	"news.published_at, COALESCE(news.pub_date, ''), COALESCE(news.image_url, ''), COALESCE(news.source_id, ''), " +
+	"COALESCE(news.source_name, ''), COALESCE(news.source_url, ''), COALESCE(news.language, ''), " +
+	listColumn("countries") + ", " + listColumn("categories") + ", COALESCE(news.sentiment, ''), " +
+	"news.pinned_at IS NOT NULL, news.hidden_at IS NOT NULL"
+
+// listColumn возвращает подзапрос, склеивающий значения измерения статьи через listSeparator
+func listColumn(dimension string) string {
+	return "COALESCE((SELECT group_concat(value, char(31) ORDER BY position) FROM " +
+		ArticleListTables[dimension] + " WHERE article_id = news.article_id), '')"
+}
+
+// listSeparator разделяет значения в колонке, построенной listColumn
+const listSeparator = "\x1f"
+
+func splitListColumn(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return strings.Split(value, listSeparator)
+}
+
+// scanArticles читает строки с колонками articleColumns
+func scanArticles(rows *sql.Rows) ([]NewsArticle, error) {
+	articles := []NewsArticle{}
+	for rows.Next() {
+		a, err := scanArticle(rows)
+		if err != nil {
+			return nil, err
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// scanArticle читает текущую строку с колонками articleColumns,
+// за которыми могут идти дополнительные колонки extra
+func scanArticle(rows *sql.Rows, extra ...interface{}) (NewsArticle, error) {
+	var a NewsArticle
+	var keywords, creators, countries, categories string
+	var published sql.NullInt64
+
+	dest := []interface{}{
+		&a.ArticleID, &a.Title, &a.Link, &keywords, &creators, &a.VideoURL,
+		&a.Description, &a.Content, &published, &a.PubDate, &a.ImageURL, &a.SourceID, &a.SourceName, &a.SourceURL,
+		&a.Language, &countries, &categories, &a.Sentiment, &a.Pinned, &a.Hidden,
+	}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return a, err
+	}
+
+	if published.Valid {
+		a.PublishedAt = time.Unix(published.Int64, 0).UTC()
+	}
+	a.Keywords = splitListColumn(keywords)
+	a.Creator = splitListColumn(creators)
+	a.Country = splitListColumn(countries)
+	a.Category = splitListColumn(categories)
+	return a, nil
+}
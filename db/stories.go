@@ -0,0 +1,188 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// StoryCandidate — ранее сохранённая статья, похожая по полосам LSH
+type StoryCandidate struct {
+	ArticleID string
+	StoryID   int64
+	Signature []byte
+}
+
+// Story — кластер статей об одном событии
+type Story struct {
+	ID               int64     `json:"id"`
+	RepresentativeID string    `json:"representative_id"`
+	Size             int       `json:"size"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// HasStory проверяет, отнесена ли статья к какому-либо сюжету
+func HasStory(db *sql.DB, articleID string) (bool, error) {
+	var n int
+	err := db.QueryRow("SELECT COUNT(*) FROM article_stories WHERE article_id = ?", articleID).Scan(&n)
+	return n > 0, err
+}
+
+// FindStoryCandidates возвращает статьи, опубликованные между from и to, у которых совпала
+// хотя бы одна полоса. Для статей без даты публикации берётся время их кластеризации.
+func FindStoryCandidates(db *sql.DB, bands []int64, from, to time.Time) ([]StoryCandidate, error) {
+	if len(bands) == 0 {
+		return nil, nil
+	}
+
+	conds := make([]string, 0, len(bands))
+	args := make([]interface{}, 0, len(bands)*2+2)
+	for i, bucket := range bands {
+		conds = append(conds, "(b.band = ? AND b.bucket = ?)")
+		args = append(args, i, bucket)
+	}
+	args = append(args, from.Unix(), to.Unix())
+
+	rows, err := db.Query(
+		`SELECT DISTINCT s.article_id, s.story_id, s.signature
+		FROM story_bands b JOIN article_stories s ON s.article_id = b.article_id
+			JOIN news ON news.article_id = s.article_id
+		WHERE (`+strings.Join(conds, " OR ")+`)
+			AND COALESCE(news.published_at, s.created_at) BETWEEN ? AND ?`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []StoryCandidate
+	for rows.Next() {
+		var c StoryCandidate
+		if err := rows.Scan(&c.ArticleID, &c.StoryID, &c.Signature); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+// AddArticleToStory привязывает статью к сюжету storyID.
+// Если storyID равен 0, создаётся новый сюжет, где статья — представитель.
+func AddArticleToStory(db *sql.DB, articleID string, storyID int64, signature []byte, bands []int64) (int64, error) {
+	err := write(db, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		if storyID == 0 {
+			result, err := tx.Exec(
+				"INSERT INTO stories (representative_id, size, created_at, updated_at) VALUES (?, 0, ?, ?)",
+				articleID, now, now,
+			)
+			if err != nil {
+				return err
+			}
+			if storyID, err = result.LastInsertId(); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.Exec(
+			"INSERT INTO article_stories (article_id, story_id, signature, created_at) VALUES (?, ?, ?, ?)",
+			articleID, storyID, signature, now.Unix(),
+		); err != nil {
+			return err
+		}
+
+		for i, bucket := range bands {
+			if _, err := tx.Exec(
+				"INSERT OR IGNORE INTO story_bands (band, bucket, article_id) VALUES (?, ?, ?)",
+				i, bucket, articleID,
+			); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(
+			"UPDATE stories SET size = size + 1, updated_at = ? WHERE id = ?",
+			now, storyID,
+		)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return storyID, nil
+}
+
+// ListStories возвращает сюжеты, начиная с обновлённых последними.
+// Если category не пуста, берутся только сюжеты, где есть статья этой категории.
+func ListStories(db *sql.DB, category string, limit, offset int) ([]Story, error) {
+	query := "SELECT id, representative_id, size, created_at, updated_at FROM stories"
+	var args []interface{}
+	if category != "" {
+		query += ` WHERE id IN (
+			SELECT s.story_id FROM article_stories s JOIN article_categories c ON c.article_id = s.article_id
+			WHERE c.value = ?)`
+		args = append(args, category)
+	}
+	query += " ORDER BY updated_at DESC LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		var s Story
+		if err := rows.Scan(&s.ID, &s.RepresentativeID, &s.Size, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, err
+		}
+		stories = append(stories, s)
+	}
+	return stories, rows.Err()
+}
+
+// ArticlesWithoutStory возвращает статьи (ID, заголовок, описание, дата публикации),
+// ещё не отнесённые к сюжету
+func ArticlesWithoutStory(db *sql.DB) ([]NewsArticle, error) {
+	rows, err := db.Query(
+		`SELECT article_id, COALESCE(title, ''), COALESCE(description, ''), published_at FROM news
+		WHERE article_id NOT IN (SELECT article_id FROM article_stories)
+		ORDER BY published_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []NewsArticle
+	for rows.Next() {
+		var a NewsArticle
+		var published sql.NullInt64
+		if err := rows.Scan(&a.ArticleID, &a.Title, &a.Description, &published); err != nil {
+			return nil, err
+		}
+		if published.Valid {
+			a.PublishedAt = time.Unix(published.Int64, 0).UTC()
+		}
+		articles = append(articles, a)
+	}
+	return articles, rows.Err()
+}
+
+// StoryArticles возвращает видимые статьи сюжета в порядке публикации
+func StoryArticles(db *sql.DB, storyID int64) ([]NewsArticle, error) {
+	rows, err := db.Query(
+		"SELECT "+articleColumns+" FROM news WHERE article_id IN (SELECT article_id FROM article_stories WHERE story_id = ?) AND hidden_at IS NULL ORDER BY published_at",
+		storyID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanArticles(rows)
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"database/sql"
+	"path"
+	"strconv"
+	"time"
+)
+
+// TelegramPost — сообщение канала из telegram_messages вместе с данными канала
+type TelegramPost struct {
+	ID              int64     // id строки telegram_messages
+	MessageID       int64     // id сообщения в канале
+	ChannelID       int64     // id канала в Telegram
+	ChannelUsername string    // имя канала без @, может быть пустым
+	ChannelTitle    string    // название канала
+	Text            string    // текст сообщения
+	Date            time.Time // время отправки в UTC, нулевое — если не распознано
+	MediaURL        string    // путь к медиафайлу, который сохранил скрипт, пусто — без медиа
+}
+
+// TelegramPostFilter — выборка сообщений для /telegram/messages
+type TelegramPostFilter struct {
+	Channel string // имя канала без @ или его id, пусто — все каналы
+	Limit   int
+	Offset  int
+}
+
+// Channel возвращает имя канала для source_id статьи и фильтра channel: username или id
+func (p TelegramPost) Channel() string {
+	if p.ChannelUsername != "" {
+		return p.ChannelUsername
+	}
+	return strconv.FormatInt(p.ChannelID, 10)
+}
+
+// ArticleID возвращает article_id статьи, в которую переносится сообщение
+func (p TelegramPost) ArticleID() string {
+	return "telegram-" + strconv.FormatInt(p.ChannelID, 10) + "-" + strconv.FormatInt(p.MessageID, 10)
+}
+
+// MediaName возвращает имя медиафайла в каталоге media_dir, пусто — без медиа
+func (p TelegramPost) MediaName() string {
+	if p.MediaURL == "" {
+		return ""
+	}
+	return path.Base(p.MediaURL)
+}
+
+// Колонки telegram_messages m и telegram_channels c в порядке, который ожидает scanTelegramPosts
+const telegramPostColumns = `m.id, COALESCE(m.message_id, 0), COALESCE(m.channel_id, 0),
+	COALESCE(c.channel_username, ''), COALESCE(c.channel_title, ''),
+	COALESCE(m.message_text, ''), unixepoch(m.message_date), COALESCE(m.media_url, '')`
+
+// PendingTelegramPosts возвращает до limit сообщений, ещё не перенесённых в news, в порядке поступления
+func PendingTelegramPosts(db *sql.DB, limit int) ([]TelegramPost, error) {
+	rows, err := db.Query(
+		`SELECT `+telegramPostColumns+`
+		FROM telegram_messages m LEFT JOIN telegram_channels c ON c.channel_id = m.channel_id
+		WHERE m.imported_at IS NULL ORDER BY m.id LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTelegramPosts(rows)
+}
+
+// MarkTelegramImported отмечает сообщения как перенесённые в news
+func MarkTelegramImported(db *sql.DB, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return write(db, func(tx *sql.Tx) error {
+		stmt, err := tx.Prepare("UPDATE telegram_messages SET imported_at = ? WHERE id = ?")
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		now := time.Now().Unix()
+		for _, id := range ids {
+			if _, err := stmt.Exec(now, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListTelegramPosts возвращает сообщения каналов, начиная с новых.
+// Сообщения, чьи статьи скрыл модератор, не возвращаются.
+func ListTelegramPosts(db *sql.DB, filter TelegramPostFilter) ([]TelegramPost, error) {
+	query := `SELECT ` + telegramPostColumns + `
+		FROM telegram_messages m LEFT JOIN telegram_channels c ON c.channel_id = m.channel_id
+		WHERE NOT EXISTS (
+			SELECT 1 FROM news WHERE news.article_id = 'telegram-' || m.channel_id || '-' || m.message_id
+				AND news.hidden_at IS NOT NULL)`
+	var args []interface{}
+	if filter.Channel != "" {
+		query += " AND (lower(c.channel_username) = lower(?) OR CAST(m.channel_id AS TEXT) = ?)"
+		args = append(args, filter.Channel, filter.Channel)
+	}
+	query += " ORDER BY unixepoch(m.message_date) DESC, m.id DESC LIMIT ? OFFSET ?"
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTelegramPosts(rows)
+}
+
+func scanTelegramPosts(rows *sql.Rows) ([]TelegramPost, error) {
+	posts := []TelegramPost{}
+	for rows.Next() {
+		var p TelegramPost
+		var date sql.NullInt64
+		if err := rows.Scan(&p.ID, &p.MessageID, &p.ChannelID, &p.ChannelUsername, &p.ChannelTitle,
+			&p.Text, &date, &p.MediaURL); err != nil {
+			return nil, err
+		}
+		if date.Valid {
+			p.Date = time.Unix(date.Int64, 0).UTC()
+		}
+		posts = append(posts, p)
+	}
+	return posts, rows.Err()
+}
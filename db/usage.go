@@ -0,0 +1,37 @@
+package db
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Провайдеры, расход кредитов которых учитывается в api_usage
+const ProviderNewsdata = "newsdata"
+
+// usageDay возвращает ключ суток (UTC), за которые считается расход
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// GetAPIUsage возвращает количество кредитов, потраченных провайдером за сутки t
+func GetAPIUsage(db *sql.DB, provider string, t time.Time) (int, error) {
+	var credits int
+	err := db.QueryRow(
+		"SELECT credits FROM api_usage WHERE provider = ? AND day = ?",
+		provider, usageDay(t),
+	).Scan(&credits)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return credits, err
+}
+
+// AddAPIUsage увеличивает расход кредитов провайдера за сутки t
+func AddAPIUsage(db *sql.DB, provider string, t time.Time, credits int) error {
+	_, err := exec(db,
+		`INSERT INTO api_usage (provider, day, credits) VALUES (?, ?, ?)
+		ON CONFLICT(provider, day) DO UPDATE SET credits = credits + excluded.credits`,
+		provider, usageDay(t), credits,
+	)
+	return err
+}
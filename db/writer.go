@@ -0,0 +1,167 @@
+package db
+
+import (
+	"database/sql"
+	"sync"
+)
+
+// Writer выполняет все записи Go-части в news.db по очереди в одной горутине,
+// чтобы загрузчики, обогащение и HTTP обработчики не спорили за блокировку файла.
+// Функции пакета db пишут через write: если для соединения запущен Writer,
+// транзакция уходит ему, иначе (команды, тесты вручную) выполняется сразу.
+type Writer struct {
+	db   *sql.DB
+	jobs chan writeJob
+	stop chan struct{}
+	done chan struct{}
+
+	stopOnce sync.Once
+}
+
+type writeJob struct {
+	fn     func(tx *sql.Tx) error
+	result chan error
+}
+
+// Писатели по соединению, для которого они запущены
+var writers sync.Map
+
+// StartWriter запускает горутину записи для database
+func StartWriter(database *sql.DB) *Writer {
+	w := &Writer{
+		db:   database,
+		jobs: make(chan writeJob),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	writers.Store(database, w)
+	go w.loop()
+	return w
+}
+
+// Stop дожидается текущей записи и останавливает горутину.
+// Последующие записи выполняются напрямую.
+func (w *Writer) Stop() {
+	w.stopOnce.Do(func() {
+		writers.Delete(w.db)
+		close(w.stop)
+		<-w.done
+	})
+}
+
+// Do выполняет fn в транзакции в горутине записи и возвращает результат.
+// fn не должна сама вызывать функции пакета, которые пишут в БД.
+func (w *Writer) Do(fn func(tx *sql.Tx) error) error {
+	job := writeJob{fn: fn, result: make(chan error, 1)}
+	select {
+	case w.jobs <- job:
+		return <-job.result
+	case <-w.stop:
+		return runTx(w.db, fn)
+	}
+}
+
+func (w *Writer) loop() {
+	defer close(w.done)
+	for {
+		select {
+		case job := <-w.jobs:
+			job.result <- runTx(w.db, job.fn)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// write выполняет fn в транзакции через Writer соединения, если он запущен
+func write(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	if w, ok := writers.Load(db); ok {
+		return w.(*Writer).Do(fn)
+	}
+	return runTx(db, fn)
+}
+
+func runTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// sqlTx — то, что нужно вспомогательным функциям записи от транзакции.
+// Его реализуют *sql.Tx и batchTx.
+type sqlTx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// batchTx готовит каждый запрос один раз за транзакцию и переиспользует его
+type batchTx struct {
+	tx    *sql.Tx
+	stmts map[string]*sql.Stmt
+}
+
+func newBatchTx(tx *sql.Tx) *batchTx {
+	return &batchTx{tx: tx, stmts: make(map[string]*sql.Stmt)}
+}
+
+func (b *batchTx) stmt(query string) (*sql.Stmt, error) {
+	if s, ok := b.stmts[query]; ok {
+		return s, nil
+	}
+	s, err := b.tx.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	b.stmts[query] = s
+	return s, nil
+}
+
+func (b *batchTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	s, err := b.stmt(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.Exec(args...)
+}
+
+func (b *batchTx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	s, err := b.stmt(query)
+	if err != nil {
+		return nil, err
+	}
+	return s.Query(args...)
+}
+
+func (b *batchTx) QueryRow(query string, args ...interface{}) *sql.Row {
+	s, err := b.stmt(query)
+	if err != nil {
+		// Ошибку подготовки вернёт Scan того же запроса без подготовки
+		return b.tx.QueryRow(query, args...)
+	}
+	return s.QueryRow(args...)
+}
+
+func (b *batchTx) close() {
+	for _, s := range b.stmts {
+		s.Close()
+	}
+}
+
+// exec выполняет один запрос записи через write
+func exec(db *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := write(db, func(tx *sql.Tx) error {
+		var err error
+		result, err = tx.Exec(query, args...)
+		return err
+	})
+	return result, err
+}
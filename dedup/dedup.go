@@ -0,0 +1,57 @@
+package dedup
+
+import (
+	"database/sql"
+	"newsAPI/db"
+	"time"
+)
+
+const (
+	// Threshold — минимальная оценка сходства, при которой статьи считаются одним сюжетом
+	Threshold = 0.5
+	// Window — насколько раньше или позже по дате публикации может выйти статья того же сюжета
+	Window = 72 * time.Hour
+)
+
+// Assign относит статью к существующему сюжету с самой похожей статьёй
+// или создаёт новый. Повторный вызов для той же статьи ничего не делает.
+func Assign(database *sql.DB, article db.NewsArticle) (int64, error) {
+	if has, err := db.HasStory(database, article.ArticleID); err != nil || has {
+		return 0, err
+	}
+
+	sig, ok := ComputeSignature(article.Title + " " + article.Description)
+	if !ok {
+		// Без текста сравнивать нечего — статья становится отдельным сюжетом
+		return db.AddArticleToStory(database, article.ArticleID, 0, sig.Bytes(), nil)
+	}
+
+	bandKeys := sig.Bands()
+	bands := bandKeys[:]
+
+	// Окно отсчитывается от публикации самой статьи, чтобы архивные статьи (backfill,
+	// cluster-stories) сравнивались со статьями своего времени, а не с сегодняшними
+	published := article.PublishedAt
+	if published.IsZero() {
+		published = time.Now()
+	}
+	candidates, err := db.FindStoryCandidates(database, bands, published.Add(-Window), published.Add(Window))
+	if err != nil {
+		return 0, err
+	}
+
+	var storyID int64
+	best := Threshold
+	for _, c := range candidates {
+		other, ok := SignatureFromBytes(c.Signature)
+		if !ok {
+			continue
+		}
+		if sim := Similarity(sig, other); sim >= best {
+			best = sim
+			storyID = c.StoryID
+		}
+	}
+
+	return db.AddArticleToStory(database, article.ArticleID, storyID, sig.Bytes(), bands)
+}
@@ -0,0 +1,56 @@
+//go:build sqlite_fts5
+
+// Миграции news.db создают таблицы FTS5, поэтому тест собирается только с тегом:
+// go test -tags sqlite_fts5 ./...
+
+package dedup
+
+import (
+	"newsAPI/db"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestAssignWindow проверяет, что окно сюжета отсчитывается от даты публикации статьи:
+// одинаковые новости с разницей в месяцы — разные сюжеты, с разницей в день — один.
+func TestAssignWindow(t *testing.T) {
+	database, err := db.OpenFile(filepath.Join(t.TempDir(), "news.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err := db.MigrateUp(database); err != nil {
+		t.Fatal(err)
+	}
+
+	text := "Центробанк повысил ключевую ставку до 21 процента годовых"
+	articles := []db.NewsArticle{
+		{ArticleID: "january", Title: text, PublishedAt: time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)},
+		{ArticleID: "may", Title: text, PublishedAt: time.Date(2025, 5, 27, 9, 0, 0, 0, time.UTC)},
+		{ArticleID: "may-next-day", Title: text, PublishedAt: time.Date(2025, 5, 28, 9, 0, 0, 0, time.UTC)},
+	}
+	results, err := db.SaveArticles(database, articles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stories := map[string]int64{}
+	for i, article := range articles {
+		if results[i].Err != nil {
+			t.Fatalf("сохранение %s: %v", article.ArticleID, results[i].Err)
+		}
+		story, err := Assign(database, article)
+		if err != nil {
+			t.Fatalf("Assign(%s): %v", article.ArticleID, err)
+		}
+		stories[article.ArticleID] = story
+	}
+
+	if stories["january"] == stories["may"] {
+		t.Errorf("статьи января и мая попали в один сюжет %d", stories["may"])
+	}
+	if stories["may"] != stories["may-next-day"] {
+		t.Errorf("статьи 27 и 28 мая в разных сюжетах: %d и %d", stories["may"], stories["may-next-day"])
+	}
+}
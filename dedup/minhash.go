@@ -0,0 +1,136 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// NumHashes — длина MinHash подписи
+	NumHashes = 64
+	// BandRows — сколько значений подписи входит в одну полосу LSH
+	BandRows = 4
+	// NumBands — число полос LSH; статьи с совпавшей полосой становятся кандидатами
+	NumBands = NumHashes / BandRows
+
+	// shingleSize — длина символьного шингла внутри слова.
+	// Символьные шинглы устойчивее к падежным окончаниям, чем словесные.
+	shingleSize = 4
+)
+
+// Signature — MinHash подпись текста
+type Signature [NumHashes]uint32
+
+// Коэффициенты хеш-функций h_i(x) = a_i*x + b_i (mod 2^32), фиксированы,
+// чтобы подписи, сохранённые в БД, оставались сравнимыми между запусками
+var hashA, hashB [NumHashes]uint32
+
+func init() {
+	// Простой детерминированный генератор (xorshift) для коэффициентов
+	state := uint32(2463534242)
+	next := func() uint32 {
+		state ^= state << 13
+		state ^= state >> 17
+		state ^= state << 5
+		return state
+	}
+	for i := 0; i < NumHashes; i++ {
+		hashA[i] = next() | 1
+		hashB[i] = next()
+	}
+}
+
+// Shingles разбивает текст на множество символьных шинглов
+func Shingles(text string) map[uint32]struct{} {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	shingles := make(map[uint32]struct{})
+	for _, w := range words {
+		runes := []rune("_" + w + "_")
+		if len(runes) <= shingleSize {
+			shingles[hashString(string(runes))] = struct{}{}
+			continue
+		}
+		for i := 0; i+shingleSize <= len(runes); i++ {
+			shingles[hashString(string(runes[i:i+shingleSize]))] = struct{}{}
+		}
+	}
+	return shingles
+}
+
+// ComputeSignature строит MinHash подпись по шинглам текста.
+// Для пустого текста возвращает false.
+func ComputeSignature(text string) (Signature, bool) {
+	var sig Signature
+	shingles := Shingles(text)
+	if len(shingles) == 0 {
+		return sig, false
+	}
+
+	for i := range sig {
+		sig[i] = ^uint32(0)
+	}
+	for s := range shingles {
+		for i := 0; i < NumHashes; i++ {
+			if h := hashA[i]*s + hashB[i]; h < sig[i] {
+				sig[i] = h
+			}
+		}
+	}
+	return sig, true
+}
+
+// Similarity оценивает коэффициент Жаккара по двум подписям
+func Similarity(a, b Signature) float64 {
+	same := 0
+	for i := range a {
+		if a[i] == b[i] {
+			same++
+		}
+	}
+	return float64(same) / NumHashes
+}
+
+// Bands возвращает ключи полос LSH для поиска кандидатов
+func (s Signature) Bands() [NumBands]int64 {
+	var bands [NumBands]int64
+	for b := 0; b < NumBands; b++ {
+		h := fnv.New64a()
+		for _, v := range s[b*BandRows : (b+1)*BandRows] {
+			h.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16), byte(v >> 24)})
+		}
+		bands[b] = int64(h.Sum64())
+	}
+	return bands
+}
+
+// Bytes кодирует подпись для хранения в BLOB
+func (s Signature) Bytes() []byte {
+	buf := make([]byte, 0, NumHashes*4)
+	for _, v := range s {
+		buf = append(buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+	}
+	return buf
+}
+
+// SignatureFromBytes декодирует подпись из BLOB
+func SignatureFromBytes(buf []byte) (Signature, bool) {
+	var sig Signature
+	if len(buf) != NumHashes*4 {
+		return sig, false
+	}
+	for i := range sig {
+		j := i * 4
+		sig[i] = uint32(buf[j]) | uint32(buf[j+1])<<8 | uint32(buf[j+2])<<16 | uint32(buf[j+3])<<24
+	}
+	return sig, true
+}
+
+func hashString(s string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(s))
+	return h.Sum32()
+}
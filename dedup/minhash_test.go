@@ -0,0 +1,56 @@
+package dedup
+
+import "testing"
+
+func TestSimilarity(t *testing.T) {
+	base := "Центробанк повысил ключевую ставку до 21 процента годовых, чтобы сдержать инфляцию"
+	tests := []struct {
+		name string
+		text string
+		min  float64
+		max  float64
+	}{
+		{"тот же текст", base, 1, 1},
+		{"пересказ", "Центробанк неожиданно повысил ключевую ставку до 21 процента годовых, чтобы сдержать рост инфляции", Threshold, 1},
+		{"другая новость", "Сборная России по хоккею выиграла товарищеский матч у команды Белоруссии", 0, Threshold - 0.2},
+	}
+
+	a, ok := ComputeSignature(base)
+	if !ok {
+		t.Fatal("ComputeSignature: нет подписи для непустого текста")
+	}
+	for _, tt := range tests {
+		b, ok := ComputeSignature(tt.text)
+		if !ok {
+			t.Fatalf("%s: нет подписи", tt.name)
+		}
+		if sim := Similarity(a, b); sim < tt.min || sim > tt.max {
+			t.Errorf("%s: сходство %.2f, ожидалось от %.2f до %.2f", tt.name, sim, tt.min, tt.max)
+		}
+	}
+}
+
+func TestComputeSignatureEmpty(t *testing.T) {
+	for _, text := range []string{"", "  ", "—!?"} {
+		if _, ok := ComputeSignature(text); ok {
+			t.Errorf("ComputeSignature(%q): подпись для текста без слов", text)
+		}
+	}
+}
+
+func TestSignatureBytes(t *testing.T) {
+	sig, _ := ComputeSignature("Курс доллара на бирже опустился ниже 80 рублей")
+	got, ok := SignatureFromBytes(sig.Bytes())
+	if !ok || got != sig {
+		t.Fatal("подпись изменилась после Bytes и SignatureFromBytes")
+	}
+	if _, ok := SignatureFromBytes(sig.Bytes()[1:]); ok {
+		t.Error("SignatureFromBytes приняла буфер неверной длины")
+	}
+
+	// Одинаковые подписи дают одинаковые полосы LSH, иначе похожие статьи не станут кандидатами
+	again, _ := ComputeSignature("Курс доллара на бирже опустился ниже 80 рублей")
+	if sig.Bands() != again.Bands() {
+		t.Error("полосы LSH одинаковых текстов различаются")
+	}
+}
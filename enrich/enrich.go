@@ -0,0 +1,214 @@
+package enrich
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"math/rand"
+	"newsAPI/collyan"
+	"newsAPI/config"
+	"newsAPI/db"
+	"newsAPI/gemini"
+	"strings"
+	"sync"
+	"time"
+)
+
+const summaryPrompt = "Сделай краткое описание в 2-3 предолжения: "
+
+// Через сколько задача в статусе running считается брошенной упавшим процессом
+const staleRunning = time.Hour
+
+// Pool — ограниченный пул воркеров, который разбирает очередь enrichment_jobs
+type Pool struct {
+	database *sql.DB
+	cfg      config.EnrichmentConfig
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewPool(database *sql.DB, cfg config.EnrichmentConfig) *Pool {
+	return &Pool{database: database, cfg: cfg, stop: make(chan struct{})}
+}
+
+// Start возвращает в очередь прерванные задачи и запускает воркеров
+func (p *Pool) Start() error {
+	if err := db.ResetRunningEnrichment(p.database, time.Time{}); err != nil {
+		return err
+	}
+
+	jobs := make(chan db.EnrichmentJob)
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for job := range jobs {
+				p.process(job)
+			}
+		}()
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer close(jobs)
+		p.dispatch(jobs)
+	}()
+
+	log.Printf("Очередь обогащения запущена (%d воркеров)", p.cfg.Workers)
+	return nil
+}
+
+// Stop останавливает выдачу задач и ждёт завершения текущих
+func (p *Pool) Stop() {
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// dispatch периодически забирает созревшие задачи и раздаёт их воркерам
+func (p *Pool) dispatch(jobs chan<- db.EnrichmentJob) {
+	for {
+		claimed, err := db.ClaimEnrichmentJobs(p.database, p.cfg.Workers)
+		if err != nil {
+			log.Printf("Ошибка при получении задач обогащения: %v", err)
+		}
+
+		for _, job := range claimed {
+			select {
+			case jobs <- job:
+			case <-p.stop:
+				// Невыданные задачи вернутся в очередь при следующем запуске
+				return
+			}
+		}
+
+		// Если очередь не опустела, сразу берём следующую порцию
+		if len(claimed) == p.cfg.Workers {
+			continue
+		}
+
+		select {
+		case <-time.After(p.cfg.PollInterval):
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// Drain синхронно обрабатывает все задачи, срок которых уже наступил, и возвращает их число.
+// Задачи, отложенные после ошибки, остаются в очереди. Выполняемые задачи не трогаются:
+// их может обрабатывать запущенный сервер, в очередь возвращаются только зависшие дольше staleRunning.
+func (p *Pool) Drain() (int, error) {
+	if err := db.ResetRunningEnrichment(p.database, time.Now().Add(-staleRunning)); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		claimed, err := db.ClaimEnrichmentJobs(p.database, p.cfg.Workers)
+		if err != nil {
+			return total, err
+		}
+		if len(claimed) == 0 {
+			return total, nil
+		}
+
+		var wg sync.WaitGroup
+		for _, job := range claimed {
+			wg.Add(1)
+			go func(job db.EnrichmentJob) {
+				defer wg.Done()
+				p.process(job)
+			}(job)
+		}
+		wg.Wait()
+		total += len(claimed)
+	}
+}
+
+// process выполняет одну задачу и записывает результат в БД
+func (p *Pool) process(job db.EnrichmentJob) {
+	started := time.Now()
+	description, err := Describe(job.Link)
+	if statErr := db.RecordScrape(p.database, job.SourceID, time.Since(started), err == nil); statErr != nil {
+		log.Printf("Ошибка записи статистики источника %s: %v", job.SourceID, statErr)
+	}
+
+	if err == nil {
+		if err := db.CompleteEnrichment(p.database, job.ArticleID, description); err != nil {
+			log.Printf("Ошибка сохранения описания %s: %v", job.ArticleID, err)
+		}
+		return
+	}
+
+	attempt := job.Attempts + 1
+	var retryAt time.Time
+	if attempt < p.cfg.MaxAttempts {
+		retryAt = time.Now().Add(p.backoff(attempt))
+		log.Printf("Обогащение %s не удалось (попытка %d), повтор после %s: %v",
+			job.ArticleID, attempt, retryAt.Format(time.RFC3339), err)
+	} else {
+		log.Printf("Обогащение %s не удалось после %d попыток: %v", job.ArticleID, attempt, err)
+	}
+
+	if err := db.FailEnrichment(p.database, job.ArticleID, err.Error(), retryAt); err != nil {
+		log.Printf("Ошибка записи результата обогащения %s: %v", job.ArticleID, err)
+	}
+}
+
+// Describe скачивает текст статьи и просит Gemini сделать краткое описание
+func Describe(link string) (string, error) {
+	if link == "" {
+		return "", errors.New("у статьи нет ссылки")
+	}
+
+	text := strings.TrimSpace(collyan.ScrapperCollyan(link))
+	if text == "" {
+		return "", errors.New("не удалось получить текст страницы")
+	}
+
+	return Summarize(text)
+}
+
+// Summarize просит Gemini сделать краткое описание текста статьи
+func Summarize(text string) (string, error) {
+	// GeminiResponse сообщает об ошибке строкой "error"
+	summary := strings.TrimSpace(gemini.GeminiResponse(summaryPrompt + text))
+	if summary == "" || summary == "error" {
+		return "", errors.New("Gemini не вернул описание")
+	}
+	return summary, nil
+}
+
+// Classify просит Gemini выбрать для текста одну категорию из списка.
+// Возвращает false, если ответ не совпал ни с одной категорией.
+func Classify(text string, categories []string) (string, bool) {
+	answer := gemini.GeminiResponse(
+		"Выбери одну категорию новости из списка: " + strings.Join(categories, ", ") +
+			". Ответь только названием категории без пояснений. Новость: " + text,
+	)
+	answer = strings.ToLower(strings.Trim(strings.TrimSpace(answer), ".\"'`"))
+	for _, category := range categories {
+		if answer == category {
+			return category, true
+		}
+	}
+	return "", false
+}
+
+// backoff — экспоненциальная задержка с джиттером перед попыткой attempt+1
+func (p *Pool) backoff(attempt int) time.Duration {
+	d := p.cfg.BackoffBase
+	for i := 1; i < attempt && d < p.cfg.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > p.cfg.BackoffMax {
+		d = p.cfg.BackoffMax
+	}
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
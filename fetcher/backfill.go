@@ -0,0 +1,145 @@
+package fetcher
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"newsAPI/config"
+	"newsAPI/db"
+	"newsAPI/parser"
+	"time"
+)
+
+// maxBackfillRetries — сколько ошибок подряд на одной странице допускается до остановки
+const maxBackfillRetries = 5
+
+// Backfill загружает архив newsdata.io за диапазон дат по дням и категориям.
+// Прогресс сохраняется в backfill_progress после каждой страницы, поэтому
+// прерванную загрузку можно продолжить тем же вызовом.
+type Backfill struct {
+	APIKey string
+	// Repo — хранилище статей, Database — локальная БД с прогрессом и журналом
+	Repo     db.Repository
+	Database *sql.DB
+	// Gate — тот же учёт квоты, что и у планировщика
+	Gate *Gate
+	// MaxPagesPerDay ограничивает глубину по каждому дню, 0 — без ограничения
+	MaxPagesPerDay int
+	// WaitForQuota — ждать сброса суточной квоты вместо выхода
+	WaitForQuota bool
+	Stop         <-chan struct{}
+}
+
+// Run проходит дни от from до to включительно, для каждого дня — все категории
+func (b *Backfill) Run(categories []config.CategoryConfig, from, to time.Time) error {
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		for _, category := range categories {
+			if err := b.runDay(category, day.Format("2006-01-02")); err != nil {
+				return fmt.Errorf("%s за %s: %w", category.Name, day.Format("2006-01-02"), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *Backfill) runDay(category config.CategoryConfig, day string) error {
+	progress, err := db.GetBackfillProgress(b.Database, category.Name, day)
+	if err != nil {
+		return err
+	}
+	// Прежние версии помечали завершённым и день, упёршийся в лимит страниц, с непустым NextPage
+	if progress.Done && progress.NextPage == "" {
+		return nil
+	}
+
+	failures := 0
+	for {
+		// День не помечаем завершённым: запуск с большим лимитом продолжит с NextPage
+		if b.MaxPagesPerDay > 0 && progress.Pages >= b.MaxPagesPerDay {
+			log.Printf("Backfill %s за %s: достигнут лимит %d стр. в день", category.Name, day, b.MaxPagesPerDay)
+			return nil
+		}
+
+		query := categoryQuery(category)
+		query.Set("from_date", day)
+		query.Set("to_date", day)
+		if progress.NextPage != "" {
+			query.Set("page", progress.NextPage)
+		}
+
+		source := &parser.NewsdataPageSource{
+			SourceName: "newsdata-archive",
+			Topic:      category.Name,
+			PageURL:    fmt.Sprintf(templateURL(newsdataArchiveURL, query), b.APIKey),
+			Gate:       b.Gate,
+		}
+		_, err := parser.FetchAndSave(source, b.Repo, b.Database)
+
+		var httpErr *parser.HTTPError
+		switch {
+		case err == nil:
+			failures = 0
+			progress.Pages++
+			progress.NextPage = source.NextPage
+			progress.Done = source.NextPage == ""
+			if err := db.SaveBackfillProgress(b.Database, progress); err != nil {
+				return err
+			}
+			if progress.Done {
+				log.Printf("Backfill %s за %s завершён (%d стр.)", category.Name, day, progress.Pages)
+				return nil
+			}
+
+		case errors.Is(err, ErrStopped):
+			return err
+
+		case errors.Is(err, ErrQuotaExhausted), errors.Is(err, ErrPaused):
+			if !b.WaitForQuota {
+				return err
+			}
+			until := b.Gate.PausedUntil()
+			log.Printf("Backfill: %v, ожидание до %s", err, until.Format(time.RFC3339))
+			if err := b.sleep(time.Until(until)); err != nil {
+				return err
+			}
+
+		case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests:
+			failures++
+			retry := httpErr.RetryAfter
+			if retry == 0 {
+				retry = b.Gate.Backoff(failures)
+			}
+			b.Gate.PauseFor(retry)
+			log.Printf("Backfill: newsdata.io вернул 429, пауза %s", retry)
+			if err := b.sleep(retry); err != nil {
+				return err
+			}
+
+		default:
+			failures++
+			if failures >= maxBackfillRetries {
+				return err
+			}
+			delay := b.Gate.Backoff(failures)
+			log.Printf("Backfill: ошибка (попытка %d), повтор через %s: %v", failures, delay.Round(time.Second), err)
+			if err := b.sleep(delay); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sleep ждёт d или остановки
+func (b *Backfill) sleep(d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(d):
+		return nil
+	case <-b.Stop:
+		return ErrStopped
+	}
+}
@@ -0,0 +1,123 @@
+package fetcher
+
+import (
+	"database/sql"
+	"log"
+	"newsAPI/config"
+	"newsAPI/db"
+	"newsAPI/parser"
+	"sync"
+	"time"
+)
+
+// FeedPoller опрашивает включённые источники из таблицы news_sources,
+// ведёт их статистику и выключает источники, которые ломаются раз за разом.
+// Список источников перечитывается на каждом проходе.
+type FeedPoller struct {
+	repo     db.Repository
+	database *sql.DB
+
+	mu      sync.Mutex
+	cfg     config.FeedsConfig
+	started bool
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewFeedPoller(repo db.Repository, database *sql.DB) *FeedPoller {
+	return &FeedPoller{
+		repo:     repo,
+		database: database,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Apply запускает опрос или применяет новую конфигурацию со следующего прохода
+func (p *FeedPoller) Apply(cfg *config.Config) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.stopped {
+		return
+	}
+	p.cfg = cfg.Feeds
+
+	if !p.started {
+		p.started = true
+		go p.loop()
+	}
+}
+
+// Stop останавливает опрос и ждёт завершения текущего прохода
+func (p *FeedPoller) Stop() {
+	p.mu.Lock()
+	if !p.started || p.stopped {
+		p.mu.Unlock()
+		return
+	}
+	p.stopped = true
+	close(p.stop)
+	p.mu.Unlock()
+
+	// Ждём без блокировки: loop читает конфигурацию через p.config()
+	<-p.done
+}
+
+func (p *FeedPoller) config() config.FeedsConfig {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cfg
+}
+
+func (p *FeedPoller) loop() {
+	defer close(p.done)
+
+	for {
+		cfg := p.config()
+		p.pollAll(cfg)
+
+		select {
+		case <-time.After(cfg.Interval):
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+func (p *FeedPoller) pollAll(cfg config.FeedsConfig) {
+	sources, err := db.GetEnabledSources(p.database)
+	if err != nil {
+		log.Printf("Ошибка при получении источников: %v", err)
+		return
+	}
+
+	for _, src := range sources {
+		select {
+		case <-p.stop:
+			return
+		default:
+		}
+
+		source, err := parser.SourceFromConfig(src)
+		if err != nil {
+			log.Printf("Пропуск источника %d: %v", src.ID, err)
+			continue
+		}
+
+		log.Printf("Запуск парсинга источника: %s", source.Name())
+		run, runErr := parser.FetchAndSave(source, p.repo, p.database)
+		if runErr != nil {
+			log.Printf("Ошибка при парсинге источника: %v", runErr)
+		}
+
+		disabled, err := db.RecordSourceRun(p.database, src.ID, run, runErr, cfg.MaxConsecutiveFailures)
+		if err != nil {
+			log.Printf("Ошибка записи статистики источника %d: %v", src.ID, err)
+		}
+		if disabled {
+			log.Printf("Источник %s выключен после %d ошибок подряд", src.Name, cfg.MaxConsecutiveFailures)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package fetcher
+
+import (
+	"database/sql"
+	"errors"
+	"math/rand"
+	"newsAPI/config"
+	"newsAPI/db"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQuotaExhausted — суточная квота кредитов (за вычетом резерва) израсходована
+	ErrQuotaExhausted = errors.New("суточная квота newsdata.io исчерпана")
+	// ErrPaused — запросы приостановлены после 429 или исчерпания квоты
+	ErrPaused = errors.New("запросы к newsdata.io приостановлены")
+	// ErrStopped — ожидание прервано остановкой планировщика
+	ErrStopped = errors.New("планировщик остановлен")
+)
+
+// Gate разносит запросы к newsdata.io во времени и ведёт учёт кредитов в таблице api_usage.
+// Реализует parser.RequestGate.
+type Gate struct {
+	database *sql.DB
+	stop     <-chan struct{}
+
+	mu          sync.Mutex
+	limits      config.RateLimitConfig
+	lastRequest time.Time // время последнего запроса или уже занятого ожидающим запросом слота
+	pausedUntil time.Time
+	waiting     int // запросы, которые прошли проверку квоты и ждут своего слота
+}
+
+// NewGate создаёт гейт; закрытие stop прерывает ожидание в Acquire
+func NewGate(database *sql.DB, limits config.RateLimitConfig, stop <-chan struct{}) *Gate {
+	return &Gate{database: database, limits: limits, stop: stop}
+}
+
+// SetLimits применяет новые ограничения из перечитанной конфигурации
+func (g *Gate) SetLimits(limits config.RateLimitConfig) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.limits = limits
+}
+
+// Acquire ждёт минимальную паузу после предыдущего запроса и списывает один кредит.
+// Возвращает ошибку, если запросы на паузе или квота на сегодня исчерпана.
+// Пауза выдерживается без блокировки, чтобы SetLimits и PausedUntil не ждали её конца.
+func (g *Gate) Acquire() error {
+	g.mu.Lock()
+	now := time.Now()
+	if now.Before(g.pausedUntil) {
+		g.mu.Unlock()
+		return ErrPaused
+	}
+
+	if g.limits.DailyQuota > 0 {
+		used, err := db.GetAPIUsage(g.database, db.ProviderNewsdata, now)
+		if err != nil {
+			g.mu.Unlock()
+			return err
+		}
+		// Ожидающие запросы ещё не записаны в api_usage, но кредит уже за ними
+		if used+g.waiting >= g.limits.DailyQuota-g.limits.QuotaReserve {
+			g.pausedUntil = nextUTCDay(now)
+			g.mu.Unlock()
+			return ErrQuotaExhausted
+		}
+	}
+
+	// Занимаем слот сразу, чтобы параллельный запрос встал после нас
+	slot := g.lastRequest.Add(g.limits.MinGap)
+	if slot.Before(now) {
+		slot = now
+	}
+	g.lastRequest = slot
+	g.waiting++
+	g.mu.Unlock()
+
+	var stopped bool
+	if wait := time.Until(slot); wait > 0 {
+		select {
+		case <-time.After(wait):
+		case <-g.stop:
+			stopped = true
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.waiting--
+	if stopped {
+		return ErrStopped
+	}
+
+	now = time.Now()
+	if now.After(g.lastRequest) {
+		g.lastRequest = now
+	}
+	return db.AddAPIUsage(g.database, db.ProviderNewsdata, now, 1)
+}
+
+// PauseFor приостанавливает запросы на d (например, по Retry-After)
+func (g *Gate) PauseFor(d time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(g.pausedUntil) {
+		g.pausedUntil = until
+	}
+}
+
+// PausedUntil возвращает момент, до которого запросы приостановлены
+func (g *Gate) PausedUntil() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.pausedUntil
+}
+
+// Backoff возвращает экспоненциальную задержку с джиттером после failures ошибок подряд
+func (g *Gate) Backoff(failures int) time.Duration {
+	g.mu.Lock()
+	base, max := g.limits.BackoffBase, g.limits.BackoffMax
+	g.mu.Unlock()
+
+	d := base
+	for i := 1; i < failures && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+
+	// Equal jitter: половина задержки фиксирована, половина случайна
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
+
+func nextUTCDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+}
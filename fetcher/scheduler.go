@@ -0,0 +1,239 @@
+package fetcher
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"newsAPI/config"
+	"newsAPI/db"
+	"newsAPI/parser"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	newsdataLatestURL  = "https://newsdata.io/api/1/latest"
+	newsdataArchiveURL = "https://newsdata.io/api/1/archive"
+)
+
+// Scheduler по очереди опрашивает категории newsdata.io по расписанию из конфигурации.
+// Все запросы идут из одной горутины через Gate, поэтому они не совпадают по времени,
+// учитывают 429/Retry-After и суточную квоту кредитов.
+type Scheduler struct {
+	apiKey   string
+	repo     db.Repository
+	database *sql.DB
+	gate     *Gate
+
+	mu      sync.Mutex
+	started bool
+	stopped bool
+	reload  chan *config.Config
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// job — состояние одной категории в планировщике
+type job struct {
+	category config.CategoryConfig
+	nextRun  time.Time
+	failures int
+}
+
+func NewScheduler(apiKey string, repo db.Repository, database *sql.DB) *Scheduler {
+	stop := make(chan struct{})
+	return &Scheduler{
+		apiKey:   apiKey,
+		repo:     repo,
+		database: database,
+		gate:     NewGate(database, config.RateLimitConfig{}, stop),
+		reload:   make(chan *config.Config, 1),
+		stop:     stop,
+		done:     make(chan struct{}),
+	}
+}
+
+// Apply запускает планировщик или передаёт ему новую конфигурацию.
+// Уже идущий запрос к API дорабатывает по старой конфигурации.
+func (s *Scheduler) Apply(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.stopped {
+		return
+	}
+
+	s.gate.SetLimits(cfg.Newsdata.RateLimit)
+
+	if !s.started {
+		s.started = true
+		go s.loop(cfg)
+		return
+	}
+
+	// Оставляем в канале только самую свежую конфигурацию
+	select {
+	case <-s.reload:
+	default:
+	}
+	s.reload <- cfg
+}
+
+// Stop останавливает планировщик и ждёт завершения текущего запроса
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started || s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.stop)
+	<-s.done
+}
+
+func (s *Scheduler) loop(cfg *config.Config) {
+	defer close(s.done)
+
+	jobs := s.plan(cfg, nil)
+
+	for {
+		next := earliest(jobs)
+
+		var timer *time.Timer
+		var wake <-chan time.Time
+		if next != nil {
+			timer = time.NewTimer(time.Until(next.nextRun))
+			wake = timer.C
+		}
+
+		select {
+		case <-s.stop:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		case cfg = <-s.reload:
+			if timer != nil {
+				timer.Stop()
+			}
+			jobs = s.plan(cfg, jobs)
+			log.Printf("Планировщик: применена новая конфигурация (%d категорий)", len(jobs))
+		case <-wake:
+			s.run(next, cfg.Newsdata.MaxPages)
+		}
+	}
+}
+
+// plan строит список задач по конфигурации, сохраняя состояние уже известных категорий.
+// Новые категории разносятся во времени на MinGap друг от друга.
+func (s *Scheduler) plan(cfg *config.Config, previous []*job) []*job {
+	state := make(map[string]*job, len(previous))
+	for _, j := range previous {
+		state[j.category.Name] = j
+	}
+
+	now := time.Now()
+	var jobs []*job
+	for _, category := range cfg.Newsdata.Categories {
+		if !category.IsEnabled() {
+			log.Printf("Категория %s выключена в конфигурации", category.Name)
+			continue
+		}
+
+		if old, ok := state[category.Name]; ok {
+			old.category = category
+			jobs = append(jobs, old)
+			continue
+		}
+
+		jobs = append(jobs, &job{
+			category: category,
+			nextRun:  now.Add(time.Duration(len(jobs)) * cfg.Newsdata.RateLimit.MinGap),
+		})
+	}
+	return jobs
+}
+
+// run выполняет одну загрузку категории и планирует следующую
+func (s *Scheduler) run(j *job, maxPages int) {
+	if until := s.gate.PausedUntil(); time.Now().Before(until) {
+		j.nextRun = until.Add(s.gate.Backoff(1))
+		return
+	}
+
+	log.Printf("Запуск парсинга для категории: %s", j.category.Name)
+	err := parser.ParseAndSaveNews(j.category.Name, CategoryURL(j.category), s.apiKey, maxPages, s.gate, s.repo, s.database)
+
+	now := time.Now()
+	var httpErr *parser.HTTPError
+	switch {
+	case err == nil:
+		j.failures = 0
+		j.nextRun = now.Add(j.category.Interval)
+
+	case errors.Is(err, ErrStopped):
+		return
+
+	case errors.Is(err, ErrQuotaExhausted), errors.Is(err, ErrPaused):
+		until := s.gate.PausedUntil()
+		log.Printf("Категория %s: %v, следующая попытка после %s", j.category.Name, err, until.Format(time.RFC3339))
+		j.nextRun = until.Add(s.gate.Backoff(1))
+
+	case errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests:
+		j.failures++
+		retry := httpErr.RetryAfter
+		if retry == 0 {
+			retry = s.gate.Backoff(j.failures)
+		}
+		s.gate.PauseFor(retry)
+		log.Printf("newsdata.io вернул 429, пауза %s", retry)
+		j.nextRun = s.gate.PausedUntil()
+
+	default:
+		j.failures++
+		delay := s.gate.Backoff(j.failures)
+		log.Printf("Ошибка при парсинге новостей (%s), попытка %d, повтор через %s: %v",
+			j.category.Name, j.failures, delay.Round(time.Second), err)
+		j.nextRun = now.Add(delay)
+	}
+}
+
+func earliest(jobs []*job) *job {
+	var next *job
+	for _, j := range jobs {
+		if next == nil || j.nextRun.Before(next.nextRun) {
+			next = j
+		}
+	}
+	return next
+}
+
+// CategoryURL строит шаблон URL newsdata.io (с %s на месте ключа) для категории
+func CategoryURL(category config.CategoryConfig) string {
+	return templateURL(newsdataLatestURL, categoryQuery(category))
+}
+
+// categoryQuery собирает параметры запроса для категории
+func categoryQuery(category config.CategoryConfig) url.Values {
+	query := url.Values{}
+	query.Set("category", category.Name)
+	if len(category.Languages) > 0 {
+		query.Set("language", strings.Join(category.Languages, ","))
+	}
+	if len(category.Countries) > 0 {
+		query.Set("country", strings.Join(category.Countries, ","))
+	}
+	return query
+}
+
+// templateURL превращает endpoint и параметры в шаблон с %s на месте ключа
+func templateURL(endpoint string, query url.Values) string {
+	// Экранируем %, чтобы шаблон пережил fmt.Sprintf в парсере
+	encoded := strings.ReplaceAll(query.Encode(), "%", "%%")
+	return fmt.Sprintf("%s?apikey=%%s&%s", endpoint, encoded)
+}
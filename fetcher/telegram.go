@@ -0,0 +1,126 @@
+package fetcher
+
+import (
+	"database/sql"
+	"log"
+	"newsAPI/config"
+	"newsAPI/db"
+	"newsAPI/parser"
+	"sync"
+	"time"
+)
+
+// Сколько сообщений Telegram переносится за одну загрузку
+const telegramBatch = 200
+
+// TelegramImporter переносит в хранилище статей сообщения каналов, которые сохранили
+// скрипты telegram/scripts, и пишет каждую пачку в fetch_runs как источник telegram
+type TelegramImporter struct {
+	repo     db.Repository
+	database *sql.DB
+
+	mu      sync.Mutex
+	cfg     config.TelegramConfig
+	started bool
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewTelegramImporter(repo db.Repository, database *sql.DB) *TelegramImporter {
+	return &TelegramImporter{
+		repo:     repo,
+		database: database,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Apply запускает перенос или применяет новую конфигурацию со следующего прохода
+func (i *TelegramImporter) Apply(cfg *config.Config) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.stopped {
+		return
+	}
+	i.cfg = cfg.Telegram
+
+	if !i.started {
+		i.started = true
+		go i.loop()
+	}
+}
+
+// Stop останавливает перенос и ждёт завершения текущего прохода
+func (i *TelegramImporter) Stop() {
+	i.mu.Lock()
+	if !i.started || i.stopped {
+		i.mu.Unlock()
+		return
+	}
+	i.stopped = true
+	close(i.stop)
+	i.mu.Unlock()
+
+	// Ждём без блокировки: loop читает конфигурацию через i.config()
+	<-i.done
+}
+
+func (i *TelegramImporter) config() config.TelegramConfig {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.cfg
+}
+
+func (i *TelegramImporter) loop() {
+	defer close(i.done)
+
+	for {
+		// С интервалом 0 перенос выключен, но конфигурацию перечитываем раз в минуту
+		wait := i.config().ImportInterval
+		if wait <= 0 {
+			wait = time.Minute
+		} else {
+			i.importAll()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-i.stop:
+			return
+		}
+	}
+}
+
+// importAll переносит пачками все накопившиеся сообщения
+func (i *TelegramImporter) importAll() {
+	source := &parser.TelegramSource{Database: i.database, BatchSize: telegramBatch}
+	for {
+		select {
+		case <-i.stop:
+			return
+		default:
+		}
+
+		run, err := parser.FetchAndSave(source, i.repo, i.database)
+		if err != nil {
+			log.Printf("Ошибка переноса сообщений Telegram: %v", err)
+			return
+		}
+
+		n, err := source.MarkImported(i.repo)
+		if err != nil {
+			log.Printf("Ошибка отметки перенесённых сообщений Telegram: %v", err)
+			return
+		}
+		if n > 0 {
+			log.Printf("Перенесено сообщений Telegram: %d, новых статей: %d", n, run.Inserted)
+		}
+		// Несохранённые сообщения остаются в очереди; повторим их в следующем проходе,
+		// а не в этом цикле, иначе пачка из одних ошибок переносилась бы бесконечно
+		if run.Failed > 0 || run.Received < telegramBatch {
+			return
+		}
+	}
+}
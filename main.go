@@ -1,14 +1,19 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"log"
 	"newsAPI/api"
+	"newsAPI/backup"
+	"newsAPI/config"
 	"newsAPI/db"
+	"newsAPI/enrich"
+	"newsAPI/fetcher"
 	"newsAPI/parser"
+	"newsAPI/retention"
 	"os"
-	"time"
+	"os/signal"
+	"syscall"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -20,6 +25,12 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
+	// Служебные команды: go run . <команда> [флаги]
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1], os.Args[2:])
+		return
+	}
+
 	// Получаем API ключ из переменных окружения
 	apiKey := os.Getenv("NEWSDATA_API_KEY")
 	if apiKey == "" {
@@ -34,13 +45,93 @@ func main() {
 	}
 	defer database.Close()
 
-	categories := []string{"top", "health", "politics", "sports", "business", "science", "food"}
+	// Все записи в news.db из этого процесса идут по очереди через один writer
+	writer := db.StartWriter(database)
+	defer writer.Stop()
+
+	// Загружаем расписание категорий
+	configPath, cfg := loadConfig()
+
+	// Для статей в PostgreSQL не работают обогащение, сюжеты, ревизии и сроки хранения:
+	// без явного согласия сервер с таким хранилищем не запускается
+	if db.IsPostgresDSN(cfg.Database.DSN) && !cfg.Database.AllowLimited {
+		log.Fatal("С PostgreSQL не работают обогащение статей без описания, сюжеты, ревизии " +
+			"и сроки хранения. Чтобы запустить сервер без них, укажите database.allow_limited: true")
+	}
+
+	// Хранилище статей, пользователей и разговоров: news.db или PostgreSQL из database.dsn
+	repo, err := db.OpenRepository(cfg.Database.DSN, database)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к хранилищу: %v", err)
+	}
+	defer repo.Close()
+
+	// С общей PostgreSQL новости загружает только один экземпляр: квота newsdata.io и очереди
+	// ведутся в его news.db. Остальные экземпляры только отвечают на запросы.
+	fetching := true
+	if pg, ok := repo.(*db.PostgresRepository); ok {
+		fetching, err = pg.LockBackgroundJobs()
+		if err != nil {
+			log.Fatalf("Ошибка блокировки фоновых загрузок: %v", err)
+		}
+		if !fetching {
+			log.Printf("Загрузки новостей выполняет другой экземпляр; этот только отвечает на запросы")
+		}
+		log.Printf("Статьи хранятся в PostgreSQL: обогащение, сюжеты, ревизии и сроки хранения отключены (database.allow_limited)")
+	}
 
-	// Запускаем горутину для каждого типа категории
-	for _, category := range categories {
-		go startNewsFetcher(apiKey, category, database)
+	// Режим записи: сырые ответы newsdata.io сохраняются как фикстуры для команды replay
+	if dir := os.Getenv("NEWSDATA_RECORD_DIR"); dir != "" {
+		if err := parser.EnableRecording(dir); err != nil {
+			log.Fatalf("Ошибка включения записи фикстур: %v", err)
+		}
 	}
 
+	// Загрузчики запускаются только у экземпляра, который загружает новости
+	var appliers []configApplier
+
+	// Запускаем планировщик загрузки категорий newsdata.io
+	scheduler := fetcher.NewScheduler(apiKey, repo, database)
+	defer scheduler.Stop()
+
+	// Опрос источников из таблицы news_sources (RSS/Atom ленты)
+	feeds := fetcher.NewFeedPoller(repo, database)
+	defer feeds.Stop()
+
+	// Перенос сообщений Telegram каналов в ленту
+	telegramImporter := fetcher.NewTelegramImporter(repo, database)
+	defer telegramImporter.Stop()
+
+	if fetching {
+		appliers = append(appliers, scheduler, feeds, telegramImporter)
+		for _, a := range appliers {
+			a.Apply(cfg)
+		}
+	}
+
+	// Фоновое обогащение статей без описания; очередь есть только для статей в news.db
+	if repo.Dialect() == db.DialectSQLite {
+		enrichPool := enrich.NewPool(database, cfg.Enrichment)
+		if err := enrichPool.Start(); err != nil {
+			log.Fatalf("Ошибка запуска очереди обогащения: %v", err)
+		}
+		defer enrichPool.Stop()
+	}
+
+	// Очистка news.db и медиафайлов Telegram по срокам хранения
+	cleaner := retention.NewCleaner(database)
+	cleaner.Apply(cfg)
+	defer cleaner.Stop()
+
+	// Горячие снимки news.db по расписанию
+	backups := backup.NewScheduler(database)
+	backups.Apply(cfg)
+	defer backups.Stop()
+
+	// По SIGHUP перечитываем конфигурацию без перезапуска HTTP сервера
+	appliers = append(appliers, cleaner, backups)
+	go reloadOnSIGHUP(configPath, appliers...)
+
 	r := gin.Default()
 
 	// Раздача статических файлов
@@ -51,19 +142,49 @@ func main() {
 	})
 
 	r.GET("/news", func(c *gin.Context) {
-		api.GetNews(c, database)
+		api.GetNews(c, repo, database)
+	})
+
+	r.GET("/news/search", func(c *gin.Context) {
+		api.SearchNews(c, repo, database)
+	})
+
+	r.GET("/news/:id/revisions", func(c *gin.Context) {
+		api.GetArticleRevisions(c, database)
+	})
+
+	r.GET("/news/counts/:dimension", func(c *gin.Context) {
+		api.GetNewsCounts(c, repo)
+	})
+
+	r.GET("/stories", func(c *gin.Context) {
+		api.GetStories(c, database)
+	})
+
+	r.GET("/categories", func(c *gin.Context) {
+		api.ListCategoriesHandler(c, database)
+	})
+
+	// Сообщения Telegram каналов
+	r.GET("/telegram/messages", func(c *gin.Context) {
+		api.GetTelegramMessages(c, database)
+	})
+	r.GET("/telegram/media/:name", func(c *gin.Context) {
+		api.ServeTelegramMedia(c, cfg.Telegram.MediaDir)
 	})
 
 	// Помощник
-	r.POST("/ask", api.GeminiAsk)
+	r.POST("/ask", api.OptionalJWTAuthMiddleware(), func(c *gin.Context) {
+		api.GeminiAsk(c, repo)
+	})
 
 	// Public routes
 	r.POST("/register", func(c *gin.Context) {
-		api.RegisterHandler(c, database)
+		api.RegisterHandler(c, repo)
 	})
 
 	r.POST("/login", func(c *gin.Context) {
-		api.LoginHandler(c, database)
+		api.LoginHandler(c, repo)
 	})
 
 	// Protected routes (require JWT)
@@ -79,23 +200,115 @@ func main() {
 			}
 			c.JSON(200, gin.H{"success": true, "message": "Welcome to your profile!", "user_id": userID})
 		})
+
+		// История вопросов к помощнику
+		protected.GET("/conversations", func(c *gin.Context) {
+			api.ListConversationsHandler(c, repo)
+		})
+		protected.DELETE("/conversations", func(c *gin.Context) {
+			api.DeleteConversationsHandler(c, repo)
+		})
+	}
+
+	// Admin routes (require JWT and users.is_admin)
+	admin := r.Group("/admin")
+	admin.Use(api.JWTAuthMiddleware(), api.AdminMiddleware(repo))
+	{
+		admin.GET("/sources", func(c *gin.Context) {
+			api.ListSourcesHandler(c, database)
+		})
+		admin.POST("/sources", func(c *gin.Context) {
+			api.AddSourceHandler(c, database)
+		})
+		admin.PATCH("/sources/:id", func(c *gin.Context) {
+			api.UpdateSourceHandler(c, database)
+		})
+		admin.DELETE("/sources/:id", func(c *gin.Context) {
+			api.DeleteSourceHandler(c, database)
+		})
+
+		admin.GET("/categories", func(c *gin.Context) {
+			api.ListCategoriesHandler(c, database)
+		})
+		admin.PUT("/categories/:id", func(c *gin.Context) {
+			api.SaveCategoryHandler(c, database)
+		})
+		admin.DELETE("/categories/:id", func(c *gin.Context) {
+			api.DeleteCategoryHandler(c, database)
+		})
+		admin.PUT("/category-mappings", func(c *gin.Context) {
+			api.SetCategoryMappingHandler(c, database)
+		})
+		admin.DELETE("/category-mappings/:provider/:name", func(c *gin.Context) {
+			api.DeleteCategoryMappingHandler(c, database)
+		})
+
+		admin.GET("/source-health", func(c *gin.Context) {
+			api.GetSourceHealth(c, database)
+		})
+
+		admin.POST("/articles", func(c *gin.Context) {
+			api.AddArticleHandler(c, repo, database)
+		})
+		admin.GET("/articles/hidden", func(c *gin.Context) {
+			api.ListHiddenArticlesHandler(c, repo)
+		})
+		admin.PATCH("/articles/:id", func(c *gin.Context) {
+			api.ModerateArticleHandler(c, repo, database)
+		})
+		admin.GET("/moderation-log", func(c *gin.Context) {
+			api.GetModerationLog(c, repo)
+		})
+
+		admin.GET("/fetch-runs", func(c *gin.Context) {
+			api.GetFetchRuns(c, database)
+		})
+		admin.GET("/fetch-runs/summary", func(c *gin.Context) {
+			api.GetFetchRunsSummary(c, database)
+		})
 	}
 
 	r.Run(":8080")
 }
 
-func startNewsFetcher(apiKey, category string, database *sql.DB) {
-	ticker := time.NewTicker(10 * time.Minute)
-	defer ticker.Stop()
+// loadConfig читает файл конфигурации (путь из FETCH_CONFIG или config.yaml)
+func loadConfig() (string, *config.Config) {
+	configPath := os.Getenv("FETCH_CONFIG")
+	if configPath == "" {
+		configPath = config.DefaultPath
+	}
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+	}
+	if w := cfg.QuotaWarning(); w != "" {
+		log.Printf("Внимание: %s", w)
+	}
+	return configPath, cfg
+}
 
-	apiURL := fmt.Sprintf("https://newsdata.io/api/1/latest?apikey=%%s&category=%s&language=ru&country=ru", category)
+// configApplier — компонент, который умеет применять перечитанную конфигурацию
+type configApplier interface {
+	Apply(cfg *config.Config)
+}
+
+// reloadOnSIGHUP перечитывает конфигурацию и передаёт её загрузчикам по сигналу SIGHUP
+func reloadOnSIGHUP(configPath string, appliers ...configApplier) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 
-	for {
-		log.Printf("Запуск парсинга для категории: %s", category)
-		err := parser.ParseAndSaveNews(apiURL, apiKey, database)
+	for range hup {
+		cfg, err := config.Load(configPath)
 		if err != nil {
-			log.Printf("Ошибка при парсинге новостей (%s): %v", category, err)
+			log.Printf("Конфигурация не применена: %v", err)
+			continue
+		}
+		if w := cfg.QuotaWarning(); w != "" {
+			log.Printf("Внимание: %s", w)
+		}
+		for _, a := range appliers {
+			a.Apply(cfg)
 		}
-		<-ticker.C
+		log.Printf("Конфигурация %s перечитана", configPath)
 	}
 }
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"strings"
+	"time"
+)
+
+// Формат pubDate, в котором newsdata.io отдаёт даты
+const pubDateLayout = "2006-01-02 15:04:05"
+
+// Форматы дат, которые встречаются у источников: RSS (RFC 1123), Atom (RFC 3339) и newsdata.io
+var pubDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	pubDateLayout,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// Смещения часовых поясов, которые встречаются в RSS в виде аббревиатур.
+// Go не знает их смещений и разбирает такие даты как UTC.
+var zoneOffsets = map[string]int{
+	"MSK":  3 * 3600,
+	"MSD":  4 * 3600,
+	"SAMT": 4 * 3600,
+	"YEKT": 5 * 3600,
+	"OMST": 6 * 3600,
+	"KRAT": 7 * 3600,
+	"IRKT": 8 * 3600,
+	"YAKT": 9 * 3600,
+	"VLAT": 10 * 3600,
+	"EET":  2 * 3600,
+	"EEST": 3 * 3600,
+	"CET":  1 * 3600,
+	"CEST": 2 * 3600,
+	"BST":  1 * 3600,
+	"EST":  -5 * 3600,
+	"EDT":  -4 * 3600,
+	"CST":  -6 * 3600,
+	"CDT":  -5 * 3600,
+	"MST":  -7 * 3600,
+	"MDT":  -6 * 3600,
+	"PST":  -8 * 3600,
+	"PDT":  -7 * 3600,
+}
+
+// Аббревиатуры, для которых нулевое смещение верно
+var utcZones = map[string]bool{"UTC": true, "GMT": true, "UT": true, "Z": true}
+
+// ParsePubDate разбирает дату публикации в UTC.
+// Даты без часового пояса считаются временем в loc.
+// Если формат или аббревиатура часового пояса не распознаны, возвращается нулевое время.
+func ParsePubDate(value string, loc *time.Location) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	for _, layout := range pubDateLayouts {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			continue
+		}
+		// Незнакомую аббревиатуру Go разбирает с нулевым смещением: берём смещение
+		// из zoneOffsets, а если его там нет, считаем дату нераспознанной
+		if strings.Contains(layout, "MST") {
+			name, offset := t.Zone()
+			if offset == 0 && !utcZones[name] {
+				known, ok := zoneOffsets[name]
+				if !ok {
+					continue
+				}
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(),
+					t.Nanosecond(), time.FixedZone(name, known))
+			}
+		}
+		return t.UTC()
+	}
+	return time.Time{}
+}
+
+// newsdataLocation возвращает часовой пояс из поля pubDateTZ, по умолчанию UTC
+func newsdataLocation(tz string) *time.Location {
+	if tz == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
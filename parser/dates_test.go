@@ -0,0 +1,34 @@
+package parser
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePubDate(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*3600)
+	tests := []struct {
+		value string
+		loc   *time.Location
+		want  time.Time
+	}{
+		{"Tue, 27 May 2025 10:00:00 +0300", time.UTC, time.Date(2025, 5, 27, 7, 0, 0, 0, time.UTC)},
+		{"Tue, 27 May 2025 10:00:00 GMT", time.UTC, time.Date(2025, 5, 27, 10, 0, 0, 0, time.UTC)},
+		{"Tue, 27 May 2025 10:00:00 MSK", time.UTC, time.Date(2025, 5, 27, 7, 0, 0, 0, time.UTC)},
+		{"Tue, 7 May 2025 10:00:00 EST", time.UTC, time.Date(2025, 5, 7, 15, 0, 0, 0, time.UTC)},
+		{"2025-05-27T10:00:00+03:00", time.UTC, time.Date(2025, 5, 27, 7, 0, 0, 0, time.UTC)},
+		{"2025-05-27 10:00:00", time.UTC, time.Date(2025, 5, 27, 10, 0, 0, 0, time.UTC)},
+		{"2025-05-27 10:00:00", moscow, time.Date(2025, 5, 27, 7, 0, 0, 0, time.UTC)}, // пояс из pubDateTZ
+		{"2025-05-27", time.UTC, time.Date(2025, 5, 27, 0, 0, 0, 0, time.UTC)},
+		{"  2025-05-27  ", time.UTC, time.Date(2025, 5, 27, 0, 0, 0, 0, time.UTC)},
+		{"Tue, 27 May 2025 10:00:00 XYZ", time.UTC, time.Time{}}, // неизвестная аббревиатура
+		{"вчера", time.UTC, time.Time{}},
+		{"", time.UTC, time.Time{}},
+	}
+	for _, tt := range tests {
+		got := ParsePubDate(tt.value, tt.loc)
+		if !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("ParsePubDate(%q, %s) = %v, ожидалось %v", tt.value, tt.loc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+//go:build sqlite_fts5
+
+// Миграции news.db создают таблицы FTS5, поэтому тест собирается только с тегом:
+// go test -tags sqlite_fts5 ./...
+
+package parser
+
+import (
+	"database/sql"
+	"newsAPI/db"
+	"path/filepath"
+	"testing"
+)
+
+// TestReplayFixtures прогоняет testdata/replay через разбор и сохранение во временную news.db.
+// Фикстуры: первая страница с двумя статьями; повтор одной из них с новым ключевым словом
+// и пересказ второй другим источником; ответ 429.
+func TestReplayFixtures(t *testing.T) {
+	database, err := db.OpenFile(filepath.Join(t.TempDir(), "news.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+	if _, err := db.MigrateUp(database); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &db.SQLiteRepository{DB: database}
+	n, err := ReplayFixtures(filepath.Join("testdata", "replay"), repo, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Fatalf("прогнано фикстур: %d, ожидалось 3", n)
+	}
+
+	// Журнал запусков: вставки, дубликаты и ошибка API
+	runs, err := db.ListFetchRuns(database, db.FetchRunFilter{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 {
+		t.Fatalf("записей fetch_runs: %d, ожидалось 3", len(runs))
+	}
+	// ListFetchRuns отдаёт запуски начиная с последнего
+	first, second, limited := runs[2], runs[1], runs[0]
+	if first.Received != 2 || first.Inserted != 2 || first.Duplicates != 0 || first.Error != "" {
+		t.Errorf("первый запуск: %+v", first)
+	}
+	if second.Received != 2 || second.Inserted != 1 || second.Duplicates != 1 || second.Error != "" {
+		t.Errorf("второй запуск: %+v", second)
+	}
+	if limited.HTTPStatus != 429 || limited.Inserted != 0 || limited.Error == "" {
+		t.Errorf("запуск с ответом 429: %+v", limited)
+	}
+
+	var count int
+	if err := database.QueryRow("SELECT COUNT(*) FROM news").Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Errorf("статей в news: %d, ожидалось 3", count)
+	}
+
+	// Повтор статьи дописывает ключевые слова к уже сохранённым
+	keywords, err := columnValues(database,
+		"SELECT value FROM article_keywords WHERE article_id = ? ORDER BY position", "replay-rate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keywords) != 2 || keywords[0] != "ставка" || keywords[1] != "инфляция" {
+		t.Errorf("ключевые слова replay-rate: %q, ожидалось [ставка инфляция]", keywords)
+	}
+
+	// Пересказ новости другим источником попадает в тот же сюжет
+	stories := map[string]int64{}
+	for _, id := range []string{"replay-rate", "replay-oil", "replay-oil-2"} {
+		var story int64
+		if err := database.QueryRow("SELECT story_id FROM article_stories WHERE article_id = ?", id).Scan(&story); err != nil {
+			t.Fatalf("сюжет статьи %s: %v", id, err)
+		}
+		stories[id] = story
+	}
+	if stories["replay-oil"] != stories["replay-oil-2"] {
+		t.Errorf("пересказ replay-oil-2 не объединён с replay-oil: сюжеты %d и %d", stories["replay-oil"], stories["replay-oil-2"])
+	}
+	if stories["replay-rate"] == stories["replay-oil"] {
+		t.Errorf("разные новости попали в один сюжет %d", stories["replay-rate"])
+	}
+}
+
+func columnValues(database *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := database.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
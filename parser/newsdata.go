@@ -0,0 +1,247 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"newsAPI/db"
+	"strconv"
+	"time"
+)
+
+// RequestGate вызывается перед каждым платным запросом к API.
+// Может подождать (разнести запросы во времени) или отказать, если квота исчерпана.
+type RequestGate interface {
+	Acquire() error
+}
+
+// HTTPError — ответ API с неуспешным HTTP статусом
+type HTTPError struct {
+	StatusCode int
+	RetryAfter time.Duration // 0, если заголовок Retry-After не передан
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API вернул статус %d: %s", e.StatusCode, e.Body)
+}
+
+// NewsdataSource — источник статей из API newsdata.io
+type NewsdataSource struct {
+	Topic    string // категория newsdata.io, для журнала fetch_runs
+	APIURL   string // шаблон URL с %s на месте ключа
+	APIKey   string
+	MaxPages int
+	Gate     RequestGate   // может быть nil
+	Repo     db.Repository // используется, чтобы остановиться на уже известной статье
+}
+
+func (s *NewsdataSource) Name() string {
+	return "newsdata"
+}
+
+func (s *NewsdataSource) Category() string {
+	return s.Topic
+}
+
+func (s *NewsdataSource) Provider() string {
+	return db.ProviderNewsdata
+}
+
+// Fetch идёт по токену nextPage не глубже MaxPages страниц и не запрашивает
+// следующую страницу, если на текущей встретилась статья, которая уже есть в таблице news.
+func (s *NewsdataSource) Fetch() (FetchResult, error) {
+	maxPages := s.MaxPages
+	if maxPages < 1 {
+		maxPages = 1
+	}
+
+	baseURL := fmt.Sprintf(s.APIURL, s.APIKey)
+	nextPage := ""
+	var result FetchResult
+
+	for page := 1; page <= maxPages; page++ {
+		pageURL := baseURL
+		if nextPage != "" {
+			pageURL += "&page=" + url.QueryEscape(nextPage)
+		}
+
+		if s.Gate != nil {
+			if err := s.Gate.Acquire(); err != nil {
+				return result, err
+			}
+		}
+
+		news, status, err := fetchPage(pageURL)
+		result.HTTPStatus = status
+		if err != nil {
+			return result, fmt.Errorf("страница %d: %w", page, err)
+		}
+
+		result.Received += len(news.Results)
+		reachedKnown := false
+		for _, article := range news.Results {
+			exists, err := s.Repo.ArticleExists(article.ArticleID)
+			if err != nil {
+				return result, fmt.Errorf("ошибка проверки статьи %s: %w", article.ArticleID, err)
+			}
+			// Известную статью всё равно отдаём на сохранение: она могла прийти
+			// в другой категории, и её нужно объединить с уже сохранённой
+			if exists {
+				reachedKnown = true
+			}
+			result.Articles = append(result.Articles, toDBArticle(article))
+		}
+
+		if reachedKnown || news.NextPage == "" {
+			break
+		}
+		nextPage = news.NextPage
+	}
+
+	return result, nil
+}
+
+// NewsdataPageSource загружает ровно одну страницу ответа newsdata.io
+// (например, архива) и запоминает токен следующей страницы.
+type NewsdataPageSource struct {
+	SourceName string
+	Topic      string
+	PageURL    string // готовый URL с ключом
+	Gate       RequestGate
+
+	NextPage string // заполняется после Fetch
+}
+
+func (s *NewsdataPageSource) Name() string {
+	return s.SourceName
+}
+
+func (s *NewsdataPageSource) Category() string {
+	return s.Topic
+}
+
+func (s *NewsdataPageSource) Provider() string {
+	return db.ProviderNewsdata
+}
+
+// Fetch запрашивает страницу и отдаёт все её статьи, без остановки на известных
+func (s *NewsdataPageSource) Fetch() (FetchResult, error) {
+	var result FetchResult
+
+	if s.Gate != nil {
+		if err := s.Gate.Acquire(); err != nil {
+			return result, err
+		}
+	}
+
+	news, status, err := fetchPage(s.PageURL)
+	result.HTTPStatus = status
+	if err != nil {
+		return result, err
+	}
+
+	result.Received = len(news.Results)
+	for _, article := range news.Results {
+		result.Articles = append(result.Articles, toDBArticle(article))
+	}
+	s.NextPage = news.NextPage
+	return result, nil
+}
+
+// newsdataClient — HTTP клиент для запросов к newsdata.io.
+// В режиме записи его транспорт сохраняет ответы в фикстуры (см. EnableRecording).
+var newsdataClient = &http.Client{}
+
+// fetchPage запрашивает и декодирует одну страницу ответа newsdata.io.
+// Возвращает также HTTP статус ответа (0, если ответа не было).
+func fetchPage(pageURL string) (*NewsResponse, int, error) {
+	resp, err := newsdataClient.Get(pageURL)
+	if err != nil {
+		// В тексте *url.Error есть адрес запроса с apikey, а ошибка попадает в лог и fetch_runs
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, 0, fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("ошибка чтения ответа: %w", err)
+	}
+
+	news, err := decodePage(resp.StatusCode, resp.Header, body)
+	return news, resp.StatusCode, err
+}
+
+// decodePage разбирает сырой ответ newsdata.io; используется и для живых ответов, и для фикстур
+func decodePage(status int, header http.Header, body []byte) (*NewsResponse, error) {
+	if status != http.StatusOK {
+		snippet := string(body)
+		if len(snippet) > 200 {
+			snippet = snippet[:200]
+		}
+		return nil, &HTTPError{
+			StatusCode: status,
+			RetryAfter: parseRetryAfter(header.Get("Retry-After")),
+			Body:       snippet,
+		}
+	}
+
+	var news NewsResponse
+	if err := json.Unmarshal(body, &news); err != nil {
+		fmt.Println("Ответ API:", string(body))
+		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+
+	if news.Status == "error" {
+		return nil, fmt.Errorf("ошибка от API")
+	}
+
+	return &news, nil
+}
+
+// parseRetryAfter разбирает Retry-After в секундах или в формате HTTP даты
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// Преобразуем article из типа NewsArticle в тип db.NewsArticle
+func toDBArticle(article NewsArticle) db.NewsArticle {
+	return db.NewsArticle{
+		ArticleID:   article.ArticleID,
+		Title:       article.Title,
+		Link:        article.Link,
+		Keywords:    article.Keywords,
+		Creator:     article.Creator,
+		VideoURL:    article.VideoURL,
+		Description: article.Description,
+		Content:     article.Content,
+		PubDate:     article.PubDate,
+		PublishedAt: ParsePubDate(article.PubDate, newsdataLocation(article.PubDateTZ)),
+		ImageURL:    article.ImageURL,
+		SourceID:    article.SourceID,
+		SourceName:  article.SourceName,
+		SourceURL:   article.SourceURL,
+		Language:    article.Language,
+		Country:     article.Country,
+		Category:    article.Category,
+		Sentiment:   article.Sentiment,
+	}
+}
@@ -0,0 +1,302 @@
+package parser
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"newsAPI/db"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// RSSSource — источник статей из RSS 2.0 или Atom ленты
+type RSSSource struct {
+	FeedName string
+	FeedURL  string
+	Topic    string // категория, к которой относятся все статьи ленты
+	Language string
+}
+
+func (s *RSSSource) Name() string {
+	return "rss:" + s.FeedName
+}
+
+func (s *RSSSource) Category() string {
+	return s.Topic
+}
+
+func (s *RSSSource) Provider() string {
+	return db.ProviderRSS
+}
+
+// Общая структура для RSS 2.0 (<rss><channel>), RSS 1.0 (<rdf:RDF>) и Atom (<feed>)
+type xmlFeed struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Link  string    `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []rssItem   `xml:"item"` // в RSS 1.0 элементы лежат в корне, а не внутри <channel>
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        string   `xml:"guid"`
+	Description string   `xml:"description"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"` // RSS 1.0 вместо pubDate
+	Creator     []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Author      string   `xml:"author"`
+	Categories  []string `xml:"category"`
+	Enclosures  []struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	Media []struct {
+		URL    string `xml:"url,attr"`
+		Medium string `xml:"medium,attr"`
+		Type   string `xml:"type,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomEntry struct {
+	ID        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Links     []atomLink `xml:"link"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Authors   []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// Fetch загружает ленту и приводит её элементы к db.NewsArticle
+func (s *RSSSource) Fetch() (FetchResult, error) {
+	var result FetchResult
+
+	resp, err := http.Get(s.FeedURL)
+	if err != nil {
+		return result, fmt.Errorf("ошибка запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	result.HTTPStatus = resp.StatusCode
+	if resp.StatusCode != http.StatusOK {
+		return result, fmt.Errorf("неожиданный статус ответа: %s", resp.Status)
+	}
+
+	// Многие русские ленты отдаются в windows-1251
+	decoder := xml.NewDecoder(resp.Body)
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+
+	var feed xmlFeed
+	if err := decoder.Decode(&feed); err != nil {
+		return result, fmt.Errorf("ошибка парсинга ленты: %w", err)
+	}
+
+	switch feed.XMLName.Local {
+	case "rss", "RDF":
+		result.Articles = s.fromRSS(feed)
+	case "feed":
+		result.Articles = s.fromAtom(feed)
+	default:
+		return result, fmt.Errorf("неизвестный формат ленты: <%s>", feed.XMLName.Local)
+	}
+
+	result.Received = len(result.Articles)
+	return result, nil
+}
+
+func (s *RSSSource) fromRSS(feed xmlFeed) []db.NewsArticle {
+	sourceName := s.sourceName(feed.Channel.Title)
+	items := append(feed.Channel.Items, feed.Items...)
+	articles := make([]db.NewsArticle, 0, len(items))
+
+	for _, item := range items {
+		link := strings.TrimSpace(item.Link)
+		if link == "" {
+			continue
+		}
+
+		creators := item.Creator
+		if len(creators) == 0 && item.Author != "" {
+			creators = []string{item.Author}
+		}
+
+		var imageURL string
+		for _, e := range item.Enclosures {
+			if strings.HasPrefix(e.Type, "image/") {
+				imageURL = e.URL
+				break
+			}
+		}
+		for _, m := range item.Media {
+			if imageURL != "" {
+				break
+			}
+			if m.Medium == "image" || strings.HasPrefix(m.Type, "image/") {
+				imageURL = m.URL
+			}
+		}
+
+		id := item.GUID
+		if id == "" {
+			id = link
+		}
+
+		published := item.PubDate
+		if published == "" {
+			published = item.Date
+		}
+
+		articles = append(articles, s.article(id, link, item.Title, item.Description, item.Content,
+			published, imageURL, creators, item.Categories, sourceName, feed.Channel.Link))
+	}
+
+	return articles
+}
+
+func (s *RSSSource) fromAtom(feed xmlFeed) []db.NewsArticle {
+	sourceName := s.sourceName(feed.Title)
+	articles := make([]db.NewsArticle, 0, len(feed.Entries))
+
+	for _, entry := range feed.Entries {
+		link := alternateLink(entry.Links)
+		if link == "" {
+			continue
+		}
+
+		var creators []string
+		for _, a := range entry.Authors {
+			creators = append(creators, a.Name)
+		}
+
+		var tags []string
+		for _, c := range entry.Categories {
+			tags = append(tags, c.Term)
+		}
+
+		var imageURL string
+		for _, l := range entry.Links {
+			if l.Rel == "enclosure" && strings.HasPrefix(l.Type, "image/") {
+				imageURL = l.Href
+				break
+			}
+		}
+
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+
+		id := entry.ID
+		if id == "" {
+			id = link
+		}
+
+		articles = append(articles, s.article(id, link, entry.Title, entry.Summary, entry.Content,
+			published, imageURL, creators, tags, sourceName, alternateLink(feed.Links)))
+	}
+
+	return articles
+}
+
+// article собирает нормализованную статью из полей элемента ленты
+func (s *RSSSource) article(id, link, title, description, content, published, imageURL string,
+	creators, tags []string, sourceName, sourceURL string) db.NewsArticle {
+	if sourceURL == "" {
+		sourceURL = s.FeedURL
+	}
+
+	description = stripHTML(description)
+	content = stripHTML(content)
+	if description == "" && content != "" {
+		description = content
+	}
+
+	return db.NewsArticle{
+		ArticleID:   feedArticleID(s.FeedURL, id),
+		Title:       stripHTML(title),
+		Link:        link,
+		Keywords:    tags,
+		Creator:     creators,
+		Description: description,
+		Content:     content,
+		PubDate:     strings.TrimSpace(published),
+		PublishedAt: ParsePubDate(published, time.UTC),
+		ImageURL:    imageURL,
+		SourceID:    feedSourceID(s.FeedURL),
+		SourceName:  sourceName,
+		SourceURL:   sourceURL,
+		Language:    s.Language,
+		Category:    append([]string{s.Topic}, tags...), // теги сопоставляются с категориями при сохранении
+	}
+}
+
+func (s *RSSSource) sourceName(feedTitle string) string {
+	if s.FeedName != "" {
+		return s.FeedName
+	}
+	return strings.TrimSpace(feedTitle)
+}
+
+func alternateLink(links []atomLink) string {
+	for _, l := range links {
+		if l.Rel == "" || l.Rel == "alternate" {
+			return strings.TrimSpace(l.Href)
+		}
+	}
+	return ""
+}
+
+// feedArticleID строит стабильный article_id для элемента ленты
+func feedArticleID(feedURL, itemID string) string {
+	sum := sha1.Sum([]byte(feedURL + "|" + strings.TrimSpace(itemID)))
+	return "rss-" + hex.EncodeToString(sum[:16])
+}
+
+// feedSourceID использует домен ленты как source_id
+func feedSourceID(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil || u.Hostname() == "" {
+		return feedURL
+	}
+	return strings.TrimPrefix(u.Hostname(), "www.")
+}
+
+var (
+	htmlTagRe    = regexp.MustCompile(`<[^>]*>`)
+	whitespaceRe = regexp.MustCompile(`\s+`)
+)
+
+// stripHTML убирает теги и сущности из текста ленты
+func stripHTML(value string) string {
+	value = htmlTagRe.ReplaceAllString(value, " ")
+	value = html.UnescapeString(value)
+	return strings.TrimSpace(whitespaceRe.ReplaceAllString(value, " "))
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"newsAPI/db"
+	"newsAPI/dedup"
+	"time"
+)
+
+// NewsSource — источник новостей, отдающий статьи в нормализованном виде
+type NewsSource interface {
+	// Name возвращает имя источника для логов и журнала fetch_runs
+	Name() string
+	// Category возвращает категорию, которую загружает источник
+	Category() string
+	// Provider возвращает провайдера, по которому категории статей
+	// сопоставляются с внутренним справочником
+	Provider() string
+	// Fetch загружает свежие статьи.
+	// При ошибке результат может содержать статьи, полученные до неё.
+	Fetch() (FetchResult, error)
+}
+
+// FetchResult — итог одной загрузки из источника
+type FetchResult struct {
+	Articles   []db.NewsArticle // статьи для сохранения (новые и обновлённые)
+	Received   int              // сколько статей пришло всего, включая уже известные
+	HTTPStatus int              // статус последнего HTTP ответа, 0 — ответа не было
+}
+
+// FetchAndSave забирает статьи из источника, сохраняет их в хранилище repo и пишет запись
+// в fetch_runs локальной БД database. Статьи, полученные до ошибки (например, до исчерпания
+// квоты), тоже сохраняются. Возвращает итог запуска в том виде, в каком он записан в журнал,
+// и ошибку загрузки. Статьи, которые не удалось сохранить, считаются в run.Failed и описаны
+// в run.Error, но ошибкой загрузки не являются: страница получена, повторять её незачем.
+func FetchAndSave(source NewsSource, repo db.Repository, database *sql.DB) (db.FetchRun, error) {
+	run := db.FetchRun{
+		Source:    source.Name(),
+		Category:  source.Category(),
+		StartedAt: time.Now(),
+	}
+
+	result, fetchErr := source.Fetch()
+
+	// Первая ошибка сохранения; статьи, которые не удалось сохранить, считаются в run.Failed
+	var firstSaveErr error
+	saveFailed := func(articleID string, err error) {
+		log.Printf("Ошибка сохранения статьи %s: %v", articleID, err)
+		run.Failed++
+		if firstSaveErr == nil {
+			firstSaveErr = err
+		}
+	}
+
+	// Категории сопоставляем заранее, чтобы вся пачка записалась одной транзакцией
+	articles := make([]db.NewsArticle, 0, len(result.Articles))
+	for _, article := range result.Articles {
+		categories, err := db.MapCategories(database, source.Provider(), article.Category)
+		if err != nil {
+			saveFailed(article.ArticleID, fmt.Errorf("сопоставление категорий: %w", err))
+			continue
+		}
+		article.Category = categories
+		article.SourceType = source.Provider()
+		articles = append(articles, article)
+	}
+
+	saved, err := repo.SaveArticles(articles)
+	if err != nil {
+		// Транзакция пачки откатилась целиком: не сохранилась ни одна статья
+		log.Printf("Ошибка сохранения пачки статей: %v", err)
+		run.Failed += len(articles)
+		if firstSaveErr == nil {
+			firstSaveErr = err
+		}
+		saved = nil
+	}
+	for i, res := range saved {
+		if res.Err != nil {
+			saveFailed(articles[i].ArticleID, res.Err)
+			continue
+		}
+		if !res.Inserted {
+			continue
+		}
+		run.Inserted++
+
+		// Ищем ту же новость от других источников; сюжеты пока есть только в SQLite
+		if repo.Dialect() != db.DialectSQLite {
+			continue
+		}
+		if _, err := dedup.Assign(database, articles[i]); err != nil {
+			log.Printf("Ошибка кластеризации статьи %s: %v", articles[i].ArticleID, err)
+		}
+	}
+
+	run.FinishedAt = time.Now()
+	run.HTTPStatus = result.HTTPStatus
+	run.Received = result.Received
+	if run.Received < len(result.Articles) {
+		run.Received = len(result.Articles)
+	}
+	run.Duplicates = run.Received - run.Inserted - run.Failed
+	if run.Duplicates < 0 {
+		run.Duplicates = 0
+	}
+
+	var saveErr error
+	if firstSaveErr != nil {
+		saveErr = fmt.Errorf("не сохранено статей: %d из %d: %w", run.Failed, len(result.Articles), firstSaveErr)
+	}
+	switch {
+	case fetchErr != nil && saveErr != nil:
+		run.Error = fetchErr.Error() + "; " + saveErr.Error()
+	case fetchErr != nil:
+		run.Error = fetchErr.Error()
+	case saveErr != nil:
+		run.Error = saveErr.Error()
+	}
+
+	if err := db.SaveFetchRun(database, run); err != nil {
+		log.Printf("Ошибка записи в журнал fetch_runs: %v", err)
+	}
+
+	if fetchErr != nil {
+		return run, fmt.Errorf("%s: %w", source.Name(), fetchErr)
+	}
+	return run, nil
+}
+
+// SourceFromConfig создаёт источник по строке таблицы news_sources
+func SourceFromConfig(cfg db.FeedSource) (NewsSource, error) {
+	switch cfg.Kind {
+	case db.SourceKindRSS:
+		return &RSSSource{
+			FeedName: cfg.Name,
+			FeedURL:  cfg.URL,
+			Topic:    cfg.Category,
+			Language: cfg.Language,
+		}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный тип источника: %q", cfg.Kind)
+	}
+}
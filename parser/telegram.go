@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"database/sql"
+	"newsAPI/db"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Путь, по которому API отдаёт медиафайлы Telegram
+const TelegramMediaPath = "/telegram/media/"
+
+// Сколько символов текста сообщения идёт в заголовок и описание статьи
+const (
+	telegramTitleLen       = 120
+	telegramDescriptionLen = 500
+)
+
+// TelegramSource переносит в news сообщения каналов, которые сохранили скрипты telegram/scripts.
+// Каждая загрузка берёт следующую пачку ещё не перенесённых сообщений; после сохранения
+// их нужно отметить через MarkImported.
+type TelegramSource struct {
+	Database  *sql.DB
+	BatchSize int
+
+	fetched []telegramFetched
+}
+
+// telegramFetched — сообщение последней загрузки и article_id его статьи (пусто — сообщение без текста)
+type telegramFetched struct {
+	id        int64
+	articleID string
+}
+
+func (s *TelegramSource) Name() string {
+	return "telegram"
+}
+
+func (s *TelegramSource) Category() string {
+	return ""
+}
+
+// Provider — категории статей сопоставляются по имени канала (category_mappings с provider = telegram)
+func (s *TelegramSource) Provider() string {
+	return db.ProviderTelegram
+}
+
+func (s *TelegramSource) Fetch() (FetchResult, error) {
+	var result FetchResult
+	posts, err := db.PendingTelegramPosts(s.Database, s.BatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	s.fetched = s.fetched[:0]
+	for _, post := range posts {
+		// Сообщения без текста (например, фото из альбома) статьёй не становятся
+		article, ok := TelegramArticle(post)
+		if ok {
+			result.Articles = append(result.Articles, article)
+		}
+		s.fetched = append(s.fetched, telegramFetched{id: post.ID, articleID: article.ArticleID})
+	}
+	result.Received = len(posts)
+	return result, nil
+}
+
+// MarkImported отмечает перенесёнными сообщения последней загрузки, статьи которых есть
+// в хранилище repo, и сообщения без текста. Сообщения, статьи которых не сохранились,
+// остаются в очереди до следующей загрузки. Возвращает, сколько сообщений отмечено.
+func (s *TelegramSource) MarkImported(repo db.Repository) (int, error) {
+	ids := make([]int64, 0, len(s.fetched))
+	for _, f := range s.fetched {
+		if f.articleID != "" {
+			exists, err := repo.ArticleExists(f.articleID)
+			if err != nil {
+				return 0, err
+			}
+			if !exists {
+				continue
+			}
+		}
+		ids = append(ids, f.id)
+	}
+
+	if err := db.MarkTelegramImported(s.Database, ids); err != nil {
+		return 0, err
+	}
+	s.fetched = s.fetched[:0]
+	return len(ids), nil
+}
+
+// TelegramArticle превращает сообщение канала в статью с source_type = telegram
+func TelegramArticle(post db.TelegramPost) (db.NewsArticle, bool) {
+	text := CleanTelegramText(post.Text)
+	if text == "" {
+		return db.NewsArticle{}, false
+	}
+
+	article := db.NewsArticle{
+		ArticleID:   post.ArticleID(),
+		Title:       truncateRunes(firstLine(text), telegramTitleLen),
+		Description: truncateRunes(text, telegramDescriptionLen),
+		Content:     text,
+		PublishedAt: post.Date,
+		SourceID:    post.Channel(),
+		SourceName:  post.ChannelTitle,
+		Category:    []string{post.Channel()},
+		SourceType:  db.ProviderTelegram,
+	}
+	if !post.Date.IsZero() {
+		article.PubDate = post.Date.Format(time.RFC3339)
+	}
+	if article.SourceName == "" {
+		article.SourceName = post.Channel()
+	}
+	if post.ChannelUsername != "" {
+		article.SourceURL = "https://t.me/" + post.ChannelUsername
+		article.Link = article.SourceURL + "/" + strconv.FormatInt(post.MessageID, 10)
+	}
+	if name := post.MediaName(); name != "" {
+		article.ImageURL = TelegramMediaPath + name
+	}
+	return article, true
+}
+
+// CleanTelegramText убирает разметку жирного и курсива, которую Telethon оставляет в тексте
+func CleanTelegramText(text string) string {
+	text = strings.NewReplacer("**", "", "__", "").Replace(text)
+	return strings.TrimSpace(text)
+}
+
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// truncateRunes обрезает строку до n символов, добавляя многоточие
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n])) + "…"
+}
@@ -0,0 +1,298 @@
+package retention
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"newsAPI/config"
+	"newsAPI/db"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// mediaGrace — файлы моложе этого не считаются осиротевшими:
+// скрипт Telegram сначала скачивает файл, а потом пишет сообщение в БД
+const mediaGrace = time.Hour
+
+// Report — итог прохода очистки (или того, что было бы сделано в режиме dry-run)
+type Report struct {
+	DryRun           bool
+	Action           string
+	Articles         map[string]int // по типу источника
+	TelegramMessages int
+	MediaFiles       []string
+	MediaBytes       int64
+	Vacuumed         bool
+}
+
+// Removed возвращает true, если проход что-то удалил (или удалил бы)
+func (r Report) Removed() bool {
+	return r.TotalArticles() > 0 || r.TelegramMessages > 0 || len(r.MediaFiles) > 0
+}
+
+// TotalArticles — сколько статей удалено или перенесено в архив
+func (r Report) TotalArticles() int {
+	total := 0
+	for _, n := range r.Articles {
+		total += n
+	}
+	return total
+}
+
+func (r Report) String() string {
+	verb := "удалено"
+	if r.Action == config.RetentionArchive {
+		verb = "в архив"
+	}
+	if r.DryRun {
+		verb = "будет " + verb
+	}
+
+	var byType []string
+	for sourceType, n := range r.Articles {
+		byType = append(byType, fmt.Sprintf("%s: %d", sourceType, n))
+	}
+	sort.Strings(byType)
+
+	s := fmt.Sprintf("статей %s: %d", verb, r.TotalArticles())
+	if len(byType) > 0 {
+		s += " (" + strings.Join(byType, ", ") + ")"
+	}
+	s += fmt.Sprintf(", сообщений Telegram: %d, медиафайлов: %d (%.1f МБ)",
+		r.TelegramMessages, len(r.MediaFiles), float64(r.MediaBytes)/(1<<20))
+	if r.Vacuumed {
+		s += ", выполнены VACUUM и ANALYZE"
+	}
+	return s
+}
+
+// Run удаляет или архивирует статьи с истёкшим сроком хранения, удаляет старые сообщения
+// Telegram и медиафайлы, на которые больше ничего не ссылается. В режиме dryRun ничего не меняет.
+func Run(database *sql.DB, cfg config.RetentionConfig, dryRun bool) (Report, error) {
+	report := Report{DryRun: dryRun, Action: cfg.Action, Articles: make(map[string]int)}
+	now := time.Now().UTC()
+
+	if err := expireArticles(database, cfg, now, &report); err != nil {
+		return report, fmt.Errorf("статьи: %w", err)
+	}
+
+	removedMedia := make(map[string]bool)
+	if err := expireTelegram(database, cfg, now, &report, removedMedia); err != nil {
+		return report, fmt.Errorf("сообщения Telegram: %w", err)
+	}
+	if err := purgeMedia(database, cfg, now, &report, removedMedia); err != nil {
+		return report, fmt.Errorf("медиафайлы: %w", err)
+	}
+
+	if cfg.Vacuum && !dryRun && report.Removed() {
+		if err := db.Vacuum(database); err != nil {
+			return report, fmt.Errorf("VACUUM: %w", err)
+		}
+		report.Vacuumed = true
+	}
+	return report, nil
+}
+
+func expireArticles(database *sql.DB, cfg config.RetentionConfig, now time.Time, report *Report) error {
+	// Сначала отбираем всё старше самого короткого срока, точный срок считается для каждой статьи
+	shortest := shortestAge(cfg)
+	if shortest == 0 {
+		return nil
+	}
+	candidates, err := db.ArticlesPublishedBefore(database, now.Add(-shortest))
+	if err != nil {
+		return err
+	}
+
+	var expired []string
+	for _, c := range candidates {
+		age := cfg.MaxAgeFor(c.SourceType, c.Categories)
+		if age == 0 || c.PublishedAt.After(now.Add(-age)) {
+			continue
+		}
+		expired = append(expired, c.ArticleID)
+		report.Articles[c.SourceType]++
+	}
+
+	if report.DryRun || len(expired) == 0 {
+		return nil
+	}
+	if cfg.Action == config.RetentionDelete {
+		return db.DeleteArticles(database, expired)
+	}
+	return db.ArchiveArticles(database, expired)
+}
+
+// shortestAge возвращает наименьший ненулевой срок из конфигурации, 0 — сроков нет
+func shortestAge(cfg config.RetentionConfig) time.Duration {
+	shortest := cfg.MaxAge
+	for _, ages := range []map[string]time.Duration{cfg.Categories, cfg.SourceTypes} {
+		for _, age := range ages {
+			if age > 0 && (shortest == 0 || age < shortest) {
+				shortest = age
+			}
+		}
+	}
+	return shortest
+}
+
+func expireTelegram(database *sql.DB, cfg config.RetentionConfig, now time.Time, report *Report, removedMedia map[string]bool) error {
+	age := cfg.MaxAgeFor(db.ProviderTelegram, nil)
+	if age == 0 {
+		return nil
+	}
+
+	messages, err := db.TelegramMessagesBefore(database, now.Add(-age))
+	if err != nil {
+		return err
+	}
+	report.TelegramMessages = len(messages)
+
+	ids := make([]int64, 0, len(messages))
+	for _, m := range messages {
+		ids = append(ids, m.ID)
+		if m.MediaURL != "" {
+			removedMedia[filepath.Base(m.MediaURL)] = true
+		}
+	}
+
+	if report.DryRun || len(ids) == 0 {
+		return nil
+	}
+	return db.DeleteTelegramMessages(database, ids)
+}
+
+// purgeMedia удаляет из MediaDir файлы, на которые не ссылается ни одно сообщение.
+// removedMedia — файлы сообщений, удалённых (или удаляемых в dry-run) на этом проходе.
+func purgeMedia(database *sql.DB, cfg config.RetentionConfig, now time.Time, report *Report, removedMedia map[string]bool) error {
+	entries, err := os.ReadDir(cfg.MediaDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	referenced, err := db.TelegramMediaNames(database)
+	if err != nil {
+		return err
+	}
+	// В dry-run сообщения ещё в БД, но их файлы тоже попадут в отчёт
+	for name := range removedMedia {
+		delete(referenced, name)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || referenced[entry.Name()] {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if now.Sub(info.ModTime()) < mediaGrace {
+			continue
+		}
+
+		path := filepath.Join(cfg.MediaDir, entry.Name())
+		if !report.DryRun {
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+		}
+		report.MediaFiles = append(report.MediaFiles, path)
+		report.MediaBytes += info.Size()
+	}
+	return nil
+}
+
+// Cleaner запускает Run по расписанию из конфигурации retention.interval.
+// Первый проход выполняется через интервал после запуска, а не сразу.
+type Cleaner struct {
+	database *sql.DB
+
+	mu      sync.Mutex
+	cfg     config.RetentionConfig
+	started bool
+	stopped bool
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func NewCleaner(database *sql.DB) *Cleaner {
+	return &Cleaner{
+		database: database,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Apply запускает очистку или применяет новую конфигурацию со следующего прохода
+func (c *Cleaner) Apply(cfg *config.Config) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+	c.cfg = cfg.Retention
+
+	if !c.started {
+		c.started = true
+		go c.loop()
+	}
+}
+
+// Stop останавливает очистку и ждёт завершения текущего прохода
+func (c *Cleaner) Stop() {
+	c.mu.Lock()
+	if !c.started || c.stopped {
+		c.mu.Unlock()
+		return
+	}
+	c.stopped = true
+	close(c.stop)
+	c.mu.Unlock()
+
+	// Ждём без блокировки: loop читает конфигурацию через c.config()
+	<-c.done
+}
+
+func (c *Cleaner) config() config.RetentionConfig {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cfg
+}
+
+func (c *Cleaner) loop() {
+	defer close(c.done)
+
+	for {
+		// С интервалом 0 очистка выключена, но конфигурацию перечитываем раз в минуту
+		wait := c.config().Interval
+		if wait <= 0 {
+			wait = time.Minute
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-c.stop:
+			return
+		}
+
+		cfg := c.config()
+		if cfg.Interval <= 0 {
+			continue
+		}
+		report, err := Run(c.database, cfg, false)
+		if err != nil {
+			log.Printf("Ошибка очистки по срокам хранения: %v", err)
+			continue
+		}
+		log.Printf("Очистка по срокам хранения: %s", report)
+	}
+}
@@ -9,6 +9,7 @@ createApp({
   data() {
     return {
       newsData: [],
+      categoryNames: {},
       currentCategory: 'all',
       currentDate: new Date(),
       offset: 0,
@@ -26,7 +27,7 @@ createApp({
     filteredNews() {
       return this.currentCategory === 'all' ?
         this.newsData :
-        this.newsData.filter(newsItem => newsItem.tags === this.currentCategory);
+        this.newsData.filter(newsItem => (newsItem.categories || [newsItem.tags]).includes(this.currentCategory));
     },
     formattedDateTime() {
       const months = [
@@ -43,12 +44,12 @@ createApp({
   },
   components: {
     'news-card': {
-      props: ['news'],
+      props: ['news', 'categoryName'],
       template: `
         <div class="news-card" @click="toggleContent">
           <div class="news-image-container" :class="{ hidden: showText }">
             <img :src="news.urlToImage" alt="News Image" class="news-image">
-            <span class="news-category">{{ news.tags }}</span>
+            <span class="news-category">{{ categoryName || news.tags }}</span>
           </div>
           <div class="news-content">
             <h3 class="news-title">{{ news.title }}</h3>
@@ -79,6 +80,13 @@ createApp({
     setCategory(tags) {
       this.currentCategory = tags;
     },
+    fetchCategories() {
+      axios.get('/categories')
+        .then(response => {
+          this.categoryNames = Object.fromEntries(response.data.map(c => [c.id, c.name_ru]));
+        })
+        .catch(err => console.error(err));
+    },
     fetchNewsAll() {
       axios.get(`/news?offset=15`)
         .then(response => {
@@ -132,6 +140,7 @@ createApp({
     }
   },
   mounted() {
+    this.fetchCategories();
     this.fetchNewsAll();
     this.intervalId = setInterval(() => {
       this.currentDate = new Date();
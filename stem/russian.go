@@ -0,0 +1,157 @@
+// Package stem — стеммер Snowball для русского языка.
+// Используется, чтобы поиск по слову находил и другие его формы.
+package stem
+
+import "strings"
+
+var (
+	perfectiveGerund1 = []string{"вшись", "вши", "в"}
+	perfectiveGerund2 = []string{"ившись", "ывшись", "ивши", "ывши", "ив", "ыв"}
+	adjective         = []string{
+		"ими", "ыми", "его", "ого", "ему", "ому",
+		"ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+	participle1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	participle2 = []string{"ивш", "ывш", "ующ"}
+	reflexive   = []string{"ся", "сь"}
+	verb1       = []string{
+		"ете", "йте", "ешь", "нно",
+		"ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть",
+		"й", "л", "н",
+	}
+	verb2 = []string{
+		"ейте", "уйте",
+		"ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют", "ены", "ить", "ыть", "ишь",
+		"ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую",
+		"ю",
+	}
+	noun = []string{
+		"иями",
+		"ями", "ами", "ией", "иям", "ием", "иях",
+		"ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом", "ах", "ях", "ию", "ью", "ия", "ья",
+		"а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я",
+	}
+	superlative  = []string{"ейше", "ейш"}
+	derivational = []string{"ость", "ост"}
+)
+
+// Russian возвращает основу русского слова. Слово приводится к нижнему регистру, ё заменяется на е.
+func Russian(word string) string {
+	w := []rune(strings.ReplaceAll(strings.ToLower(word), "ё", "е"))
+	rv, r2 := regions(w)
+	if rv >= len(w) {
+		return string(w)
+	}
+
+	// Шаг 1: деепричастие, иначе возвратная частица и окончание прилагательного, глагола или существительного
+	if n := findEnding(w, rv, perfectiveGerund1, true); n > 0 {
+		w = w[:len(w)-n]
+	} else if n := findEnding(w, rv, perfectiveGerund2, false); n > 0 {
+		w = w[:len(w)-n]
+	} else {
+		if n := findEnding(w, rv, reflexive, false); n > 0 {
+			w = w[:len(w)-n]
+		}
+		if n := findEnding(w, rv, adjective, false); n > 0 {
+			w = w[:len(w)-n]
+			if n := findEnding(w, rv, participle1, true); n > 0 {
+				w = w[:len(w)-n]
+			} else if n := findEnding(w, rv, participle2, false); n > 0 {
+				w = w[:len(w)-n]
+			}
+		} else if n := findVerb(w, rv); n > 0 {
+			w = w[:len(w)-n]
+		} else if n := findEnding(w, rv, noun, false); n > 0 {
+			w = w[:len(w)-n]
+		}
+	}
+
+	// Шаг 2: конечное и
+	if len(w) > rv && w[len(w)-1] == 'и' {
+		w = w[:len(w)-1]
+	}
+
+	// Шаг 3: словообразовательный суффикс в R2
+	if n := findEnding(w, r2, derivational, false); n > 0 {
+		w = w[:len(w)-n]
+	}
+
+	// Шаг 4: нн, превосходная степень, мягкий знак
+	if n := findEnding(w, rv, superlative, false); n > 0 {
+		w = w[:len(w)-n]
+	}
+	if hasSuffix(w, rv, "нн") {
+		w = w[:len(w)-1]
+	} else if len(w) > rv && w[len(w)-1] == 'ь' {
+		w = w[:len(w)-1]
+	}
+
+	return string(w)
+}
+
+// findVerb ищет самое длинное глагольное окончание из обеих групп
+func findVerb(w []rune, rv int) int {
+	n1 := findEnding(w, rv, verb1, true)
+	n2 := findEnding(w, rv, verb2, false)
+	if n1 > n2 {
+		return n1
+	}
+	return n2
+}
+
+// findEnding возвращает длину самого длинного окончания из списка, целиком лежащего в регионе.
+// Для окончаний первой группы перед окончанием в регионе должна стоять а или я.
+func findEnding(w []rune, region int, endings []string, afterAYa bool) int {
+	best := 0
+	for _, e := range endings {
+		n := len([]rune(e))
+		if n <= best || !hasSuffix(w, region, e) {
+			continue
+		}
+		if afterAYa {
+			i := len(w) - n - 1
+			if i < region || (w[i] != 'а' && w[i] != 'я') {
+				continue
+			}
+		}
+		best = n
+	}
+	return best
+}
+
+func hasSuffix(w []rune, region int, suffix string) bool {
+	s := []rune(suffix)
+	if len(w)-len(s) < region {
+		return false
+	}
+	return string(w[len(w)-len(s):]) == suffix
+}
+
+// regions возвращает начало RV (после первой гласной) и R2 по правилам Snowball
+func regions(w []rune) (rv, r2 int) {
+	rv, r1 := len(w), len(w)
+	for i, r := range w {
+		if isVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	r1 = nextRegion(w, 0)
+	r2 = nextRegion(w, r1)
+	return rv, r2
+}
+
+// nextRegion находит позицию после первой согласной, следующей за гласной, начиная с from
+func nextRegion(w []rune, from int) int {
+	for i := from + 1; i < len(w); i++ {
+		if !isVowel(w[i]) && isVowel(w[i-1]) {
+			return i + 1
+		}
+	}
+	return len(w)
+}
+
+func isVowel(r rune) bool {
+	return strings.ContainsRune("аеиоуыэюя", r)
+}
@@ -0,0 +1,30 @@
+package stem
+
+import "testing"
+
+func TestRussian(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"новости", "новост"},
+		{"новостей", "новост"},
+		{"книги", "книг"},
+		{"президента", "президент"},
+		{"выборы", "выбор"},
+		{"Москва", "москв"},         // нижний регистр
+		{"ёлка", "елк"},             // ё → е
+		{"пробежавшись", "пробежа"}, // деепричастие после а
+		{"сделавши", "сдела"},       // деепричастие после а
+		{"красивейший", "красив"},   // превосходная степень
+		{"бегающий", "бега"},        // причастие
+		{"радость", "радост"},       // «ость» вне R2 не отрезается
+		{"он", "он"},                // нет гласной перед окончанием
+		{"а", "а"},
+	}
+	for _, tt := range tests {
+		if got := Russian(tt.word); got != tt.want {
+			t.Errorf("Russian(%q) = %q, ожидалось %q", tt.word, got, tt.want)
+		}
+	}
+}
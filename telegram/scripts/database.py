@@ -14,40 +14,31 @@ class Database:
         logger.info(f"Using database file: {self.db_file}")
         self.init_db()
 
+    def _connect(self):
+        """Соединение с news.db: WAL и ожидание блокировки, чтобы не мешать Go-серверу"""
+        conn = sqlite3.connect(self.db_file, timeout=30)
+        conn.execute('PRAGMA journal_mode=WAL')
+        conn.execute('PRAGMA busy_timeout=30000')
+        return conn
+
     def init_db(self):
-        """Инициализация базы данных и создание таблиц"""
+        """Проверка схемы: таблицы создаёт и обновляет Go-приложение миграциями (./newsAPI migrate up)"""
         try:
-            conn = sqlite3.connect(self.db_file)
+            conn = self._connect()
             cursor = conn.cursor()
 
-            # Создаем таблицу для каналов, если она не существует
             cursor.execute('''
-                CREATE TABLE IF NOT EXISTS telegram_channels (
-                    id INTEGER PRIMARY KEY AUTOINCREMENT,
-                    channel_id INTEGER UNIQUE,
-                    channel_username TEXT,
-                    channel_title TEXT,
-                    last_message_id INTEGER DEFAULT 0,
-                    added_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-                    is_active BOOLEAN DEFAULT 1
-                )
+                SELECT name FROM sqlite_master
+                WHERE type = 'table' AND name IN ('telegram_channels', 'telegram_messages')
             ''')
-
-            # Создаем таблицу для сообщений, если она не существует
-            cursor.execute('''
-                CREATE TABLE IF NOT EXISTS telegram_messages (
-                    id INTEGER PRIMARY KEY AUTOINCREMENT,
-                    message_id INTEGER,
-                    channel_id INTEGER,
-                    message_text TEXT,
-                    message_date TIMESTAMP,
-                    media_url TEXT,
-                    FOREIGN KEY (channel_id) REFERENCES telegram_channels (channel_id)
+            found = {row[0] for row in cursor.fetchall()}
+            missing = {'telegram_channels', 'telegram_messages'} - found
+            if missing:
+                raise RuntimeError(
+                    f"В {self.db_file} нет таблиц {', '.join(sorted(missing))}: "
+                    "сначала создайте схему командой ./newsAPI migrate up"
                 )
-            ''')
-
-            conn.commit()
-            logger.info("Database initialized successfully")
+            logger.info("Database schema found")
         except Exception as e:
             logger.error(f"Error initializing database: {str(e)}")
             raise
@@ -57,7 +48,7 @@ class Database:
     def add_channel(self, channel_id, username, title):
         """Добавление нового канала"""
         try:
-            conn = sqlite3.connect(self.db_file)
+            conn = self._connect()
             cursor = conn.cursor()
             
             cursor.execute('''
@@ -78,7 +69,7 @@ class Database:
     def update_last_message_id(self, channel_id, last_message_id):
         """Обновление last_message_id для канала"""
         try:
-            conn = sqlite3.connect(self.db_file)
+            conn = self._connect()
             cursor = conn.cursor()
             
             cursor.execute('''
@@ -99,7 +90,7 @@ class Database:
     def get_channels(self):
         """Получение списка активных каналов"""
         try:
-            conn = sqlite3.connect(self.db_file)
+            conn = self._connect()
             cursor = conn.cursor()
             
             cursor.execute('''
@@ -118,7 +109,7 @@ class Database:
     def add_message(self, message_id, channel_id, text, date, media_url=None):
         """Добавление нового сообщения"""
         try:
-            conn = sqlite3.connect(self.db_file)
+            conn = self._connect()
             cursor = conn.cursor()
             
             cursor.execute('''